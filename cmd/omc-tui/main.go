@@ -7,10 +7,14 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/chamdom/omc-agent-tui/internal/bridge"
 	"github.com/chamdom/omc-agent-tui/internal/collector"
+	"github.com/chamdom/omc-agent-tui/internal/diff"
 	"github.com/chamdom/omc-agent-tui/internal/normalizer"
 	"github.com/chamdom/omc-agent-tui/internal/replay"
 	"github.com/chamdom/omc-agent-tui/internal/store"
@@ -26,12 +30,52 @@ var (
 )
 
 func main() {
-	watchPath := flag.String("watch", "", "Directory to watch for JSONL event files")
-	replayFile := flag.String("replay", "", "JSONL file to replay")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "diff":
+			if err := runDiff(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Diff error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "hook":
+			// Claude Code hook: reads the hook JSON on stdin
+			if err := bridge.HandleHook(os.Stdin); err != nil {
+				fmt.Fprintf(os.Stderr, "omc-tui hook: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	watchPath := flag.String("watch", "", "Directory to watch for JSONL event files (.jsonl or .jsonl.gz)")
+	trackingFile := flag.String("tracking", "", "Watch an OMC subagent-tracking.json and show agents as it changes")
+	var replayFiles stringList
+	flag.Var(&replayFiles, "replay", "JSONL file, directory or glob to replay (.jsonl or .jsonl.gz); repeatable, extra arguments are added")
+	var replayOpts replayOptions
+	flag.BoolVar(&replayOpts.strict, "strict", false, "Abort --replay on the first invalid line instead of skipping it")
+	flag.StringVar(&replayOpts.from, "from", "", "Start --replay at this event index or RFC3339 time")
+	flag.StringVar(&replayOpts.to, "to", "", "Stop --replay before this event index or RFC3339 time")
+	flag.BoolVar(&replayOpts.loop, "loop", false, "Loop --replay over its range")
+	flag.DurationVar(&replayOpts.idleGap, "idle-gap", 2*time.Second, "Compress --replay gaps between events longer than this (0 disables)")
+	flag.DurationVar(&replayOpts.idleTo, "idle-compress", 2*time.Second, "Play gaps longer than --idle-gap in this much time")
+	headless := flag.Bool("headless", false, "Render --replay to text frames without a terminal")
+	framesDir := flag.String("frames", "", "Directory for --headless frames (default: stdout)")
+	var frameOpts tui.HeadlessOptions
+	flag.IntVar(&frameOpts.Width, "width", 120, "Screen width for --headless")
+	flag.IntVar(&frameOpts.Height, "height", 40, "Screen height for --headless")
+	flag.DurationVar(&frameOpts.Every, "frame-every", 0, "Take a --headless frame at this step of session time (default: after each event)")
 	convertFile := flag.String("convert", "", "Convert subagent-tracking.json to JSONL (output to stdout or -o)")
-	convertOut := flag.String("o", "", "Output path for --convert (default: stdout)")
+	convertOut := flag.String("o", "", "Output path for --convert (default: stdout; .gz compresses)")
+	storeDir := flag.String("store-dir", "", "Persist events to a segment log in this directory and restore them on start")
+	snapshotFile := flag.String("snapshot", "", "Save store state to this file on exit and on ctrl+s (.gz compresses)")
+	openFile := flag.String("open", "", "Open a store snapshot saved with --snapshot")
 	showVersion := flag.Bool("version", false, "Print version and exit")
 	flag.Parse()
+	if len(replayFiles) > 0 {
+		// Shell-expanded globs arrive as extra arguments
+		replayFiles = append(replayFiles, flag.Args()...)
+	}
 
 	if *showVersion {
 		fmt.Printf("omc-tui %s (%s)\n", version, commit)
@@ -47,43 +91,107 @@ func main() {
 		return
 	}
 
-	s := store.NewStore(10000)
+	if *headless {
+		if len(replayFiles) == 0 {
+			fmt.Fprintln(os.Stderr, "--headless requires --replay")
+			os.Exit(1)
+		}
+		if err := runHeadless(replayFiles, replayOpts, frameOpts, *framesDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Headless error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *storeDir != "" && len(replayFiles) > 0 {
+		// Seeking backwards rebuilds the store, which a persistent log cannot undo
+		fmt.Fprintln(os.Stderr, "--store-dir cannot be combined with --replay")
+		os.Exit(1)
+	}
+
+	s, err := openStore(*storeDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Store error: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = s.Close() }()
+
+	if *openFile != "" {
+		if err := s.LoadSnapshot(*openFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Open snapshot error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	m := tui.NewModel(s)
+	m.SyncFromStore()
+	m.SetSnapshotPath(*snapshotFile)
+	if *openFile != "" {
+		// Historical events: measure stalls against event time, not the wall clock
+		m.SetClock(nil)
+	}
+
+	live := *watchPath != "" || *trackingFile != ""
+
+	if len(replayFiles) > 0 && !live {
+		player, err := loadReplay(replayFiles, replayOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Replay error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = player.Close() }()
+		m.SetReplay(player)
+	}
 
 	// Add demo events before creating program (so they're in initial state)
-	if *watchPath == "" && *replayFile == "" {
+	if !live && len(replayFiles) == 0 && *storeDir == "" && *openFile == "" {
 		addDemoEvents(&m)
 	}
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
 	// Start pipeline after program is created (pipeline sends events via p.Send)
-	var cleanup func()
-	switch {
-	case *watchPath != "":
-		cleanup = startLivePipeline(p, *watchPath)
-	case *replayFile != "":
-		if err := startReplay(p, *replayFile); err != nil {
-			fmt.Fprintf(os.Stderr, "Replay error: %v\n", err)
-			os.Exit(1)
+	var cleanups []func()
+	if *watchPath != "" {
+		cleanups = append(cleanups, startLivePipeline(p, collector.NewFileCollector(*watchPath)))
+	}
+	if *trackingFile != "" {
+		cleanups = append(cleanups, startLivePipeline(p, collector.NewTrackingCollector(*trackingFile)))
+	}
+
+	_, runErr := p.Run()
+
+	for _, cleanup := range cleanups {
+		if cleanup != nil {
+			cleanup()
 		}
 	}
 
-	if _, err := p.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if *snapshotFile != "" {
+		if err := s.SaveSnapshot(*snapshotFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Snapshot error: %v\n", err)
+		}
+	}
+
+	if runErr != nil {
+		_ = s.Close()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", runErr)
 		os.Exit(1)
 	}
+}
 
-	if cleanup != nil {
-		cleanup()
+// openStore returns an in-memory store, or a persistent one when dir is set.
+func openStore(dir string) (*store.Store, error) {
+	if dir == "" {
+		return store.NewStore(10000), nil
 	}
+	return store.Open(dir, 10000)
 }
 
 // startLivePipeline starts the Collector -> Normalizer -> TUI pipeline.
 // Returns a cleanup function to stop the collector on exit.
-func startLivePipeline(p *tea.Program, watchPath string) func() {
+func startLivePipeline(p *tea.Program, coll collector.Collector) func() {
 	norm := normalizer.New()
-	coll := collector.NewFileCollector(watchPath)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	if err := coll.Start(ctx); err != nil {
@@ -108,38 +216,116 @@ func startLivePipeline(p *tea.Program, watchPath string) func() {
 	}
 }
 
-// startReplay loads a JSONL file and sends events to the TUI with original timing.
-func startReplay(p *tea.Program, filePath string) error {
+// stringList is a flag that may be given more than once.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// replayOptions configures how --replay plays its files.
+type replayOptions struct {
+	strict   bool
+	from, to string // event index or RFC3339 time; "" leaves the side open
+	loop     bool
+	idleGap  time.Duration
+	idleTo   time.Duration
+}
+
+// loadReplay loads JSONL files, directories or globs into a player that
+// the TUI drives, merging several files onto one timeline.
+// Invalid lines are skipped and reported unless opts.strict is set.
+func loadReplay(sources []string, opts replayOptions) (*replay.Player, error) {
+	paths, err := replay.ExpandPaths(sources)
+	if err != nil {
+		return nil, fmt.Errorf("load replay: %w", err)
+	}
 	player := replay.NewPlayer()
-	if err := player.LoadFile(filePath); err != nil {
-		return fmt.Errorf("load replay: %w", err)
+	player.SetTolerant(!opts.strict)
+	if err := player.LoadFiles(paths); err != nil {
+		return nil, fmt.Errorf("load replay: %w", err)
 	}
 
-	go func() {
-		total := player.Total()
-		var lastTs time.Time
-		for i := 0; i < total; i++ {
-			player.Seek(i)
-			evt := player.CurrentEvent()
-			if evt == nil {
-				continue
-			}
-			// Respect original timing between events (capped at 2s)
-			if i > 0 && !lastTs.IsZero() {
-				delay := evt.Ts.Sub(lastTs)
-				if delay > 0 {
-					if delay > 2*time.Second {
-						delay = 2 * time.Second
-					}
-					time.Sleep(delay)
-				}
-			}
-			lastTs = evt.Ts
-			p.Send(tui.EventMsg(*evt))
+	from, err := rangeBound(player, opts.from, 0)
+	if err != nil {
+		return nil, fmt.Errorf("--from: %w", err)
+	}
+	to, err := rangeBound(player, opts.to, -1)
+	if err != nil {
+		return nil, fmt.Errorf("--to: %w", err)
+	}
+	player.SetRange(from, to)
+	player.SetLoop(opts.loop)
+	player.SetIdleCompression(opts.idleGap, opts.idleTo)
+	return player, nil
+}
+
+// rangeBound resolves a --from or --to value, an event index or an
+// RFC3339 time, to an event index. An empty value returns open.
+func rangeBound(player *replay.Player, value string, open int) (int, error) {
+	if value == "" {
+		return open, nil
+	}
+	if index, err := strconv.Atoi(value); err == nil {
+		if index < 0 {
+			return 0, fmt.Errorf("negative event index %d", index)
 		}
-	}()
+		return index, nil
+	}
+	ts, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is neither an event index nor an RFC3339 time", value)
+	}
+	return player.IndexOf(ts), nil
+}
 
-	return nil
+// runHeadless renders a replay to text frames, one file per frame in
+// dir, or to stdout separated by frame headers when dir is empty.
+func runHeadless(sources []string, opts replayOptions, frameOpts tui.HeadlessOptions, dir string) error {
+	player, err := loadReplay(sources, opts)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = player.Close() }()
+	for _, skipped := range player.Report().Skipped {
+		fmt.Fprintf(os.Stderr, "skipped %s\n", skipped)
+	}
+
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create frames dir: %w", err)
+		}
+	}
+
+	m := tui.NewModel(store.NewStore(10000))
+	return tui.RenderFrames(&m, player, frameOpts, func(frame tui.Frame) error {
+		if dir == "" {
+			_, err := fmt.Printf("--- frame %d: %d events, %s ---\n%s\n",
+				frame.Index, frame.Cursor, frame.Time.Format(time.RFC3339), frame.View)
+			return err
+		}
+		path := filepath.Join(dir, fmt.Sprintf("frame-%05d.txt", frame.Index))
+		return os.WriteFile(path, []byte(frame.View+"\n"), 0644)
+	})
+}
+
+// runDiff compares two sessions, as in "omc-tui diff good.jsonl bad.jsonl",
+// and prints the report to stdout.
+func runDiff(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: omc-tui diff <a.jsonl> <b.jsonl>")
+	}
+	result, err := diff.Compare(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	for _, skipped := range append(result.A.Skipped, result.B.Skipped...) {
+		fmt.Fprintf(os.Stderr, "skipped %s\n", skipped)
+	}
+	return result.Write(os.Stdout)
 }
 
 // runConvert converts a subagent-tracking.json file to JSONL.
@@ -2,12 +2,16 @@ package bridge
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/chamdom/omc-agent-tui/internal/replay"
 	"github.com/chamdom/omc-agent-tui/pkg/schema"
 )
 
@@ -443,3 +447,177 @@ func TestEventFactories_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestToolEvents_SharedSpan(t *testing.T) {
+	span := NewSpanID()
+	if !strings.HasPrefix(span, "span-") {
+		t.Fatalf("span id = %q, want span- prefix", span)
+	}
+	if other := NewSpanID(); other == span {
+		t.Fatal("span ids should be unique")
+	}
+
+	call := NewToolCallEvent("a1", "oh-my-claudecode:executor", "ultrawork", "Edit", span)
+	result := NewToolResultEvent("a1", "oh-my-claudecode:executor", "ultrawork", "Edit", span, false)
+
+	if call.Type != schema.TypeToolCall || result.Type != schema.TypeToolResult {
+		t.Fatalf("types = %q/%q, want tool_call/tool_result", call.Type, result.Type)
+	}
+	if call.SpanID != span || result.SpanID != span {
+		t.Errorf("span ids = %q/%q, want %q", call.SpanID, result.SpanID, span)
+	}
+
+	var payload schema.ToolResultPayload
+	if err := json.Unmarshal(result.Payload, &payload); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if payload.ToolName != "Edit" || payload.Success {
+		t.Errorf("payload = %+v, want Edit/false", payload)
+	}
+
+	for _, evt := range []schema.CanonicalEvent{call, result} {
+		if err := evt.Validate(); err != nil {
+			t.Errorf("validation failed: %v", err)
+		}
+	}
+}
+
+func TestEmitEvent_ConcurrentEmitters(t *testing.T) {
+	eventDir := filepath.Join(t.TempDir(), "events")
+	big := strings.Repeat("x", 16*1024) // far over PIPE_BUF
+
+	const emitters, perEmitter = 300, 4
+	var wg sync.WaitGroup
+	errs := make(chan error, emitters*perEmitter)
+	for i := 0; i < emitters; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			agentID := fmt.Sprintf("agent-%d", i)
+			for j := 0; j < perEmitter; j++ {
+				evt := NewUpdateEvent(agentID, "executor", "", schema.StateRunning)
+				if j%2 == 1 {
+					evt = NewErrorEvent(agentID, "executor", "", big)
+				}
+				if err := EmitEvent(eventDir, "sess", evt); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("EmitEvent error: %v", err)
+	}
+
+	// A torn line would fail the load
+	player := replay.NewPlayer()
+	if err := player.LoadFile(filepath.Join(eventDir, "sess.jsonl")); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if got := player.Total(); got != emitters*perEmitter {
+		t.Errorf("loaded %d events, want %d", got, emitters*perEmitter)
+	}
+}
+
+func TestLockExcl(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sess.jsonl.lock")
+
+	// Holders must never overlap
+	var held atomic.Int32
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := lockExcl(path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if n := held.Add(1); n != 1 {
+				errs <- fmt.Errorf("%d holders at once", n)
+			}
+			time.Sleep(time.Millisecond)
+			held.Add(-1)
+			unlock()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+
+	// A lock left by a crashed holder is taken over once stale
+	if err := os.WriteFile(path, nil, 0640); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * exclStale)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		unlock, err := lockExcl(path)
+		if err == nil {
+			unlock()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("stale lock was not taken over")
+	}
+}
+
+func TestDiffTracking(t *testing.T) {
+	running := func(id string) TrackedAgent {
+		return TrackedAgent{AgentID: id, AgentType: "executor", StartedAt: "2026-02-17T20:33:41Z", Status: "running"}
+	}
+	ended := func(id, status string) TrackedAgent {
+		a := running(id)
+		a.Status = status
+		a.CompletedAt = "2026-02-17T20:40:00Z"
+		return a
+	}
+
+	steps := []struct {
+		name   string
+		prev   []TrackedAgent
+		next   []TrackedAgent
+		expect []schema.EventType // agent order follows next
+	}{
+		{"first version", nil, []TrackedAgent{running("a"), ended("b", "completed")},
+			[]schema.EventType{schema.TypeTaskSpawn, schema.TypeTaskSpawn, schema.TypeTaskDone}},
+		{"unchanged", []TrackedAgent{running("a")}, []TrackedAgent{running("a")}, nil},
+		{"spawned", []TrackedAgent{running("a")}, []TrackedAgent{running("a"), running("c")},
+			[]schema.EventType{schema.TypeTaskSpawn}},
+		{"failed", []TrackedAgent{running("a")}, []TrackedAgent{ended("a", "failed")},
+			[]schema.EventType{schema.TypeError}},
+		{"already ended", []TrackedAgent{ended("a", "completed")}, []TrackedAgent{ended("a", "completed")}, nil},
+		{"removed", []TrackedAgent{running("a")}, nil, nil},
+		{"bad timestamp", []TrackedAgent{running("a")},
+			[]TrackedAgent{{AgentID: "a", AgentType: "executor", StartedAt: "2026-02-17T20:33:41Z", Status: "completed", CompletedAt: "yesterday"}, running("c")},
+			[]schema.EventType{schema.TypeTaskSpawn}},
+	}
+	for _, tt := range steps {
+		t.Run(tt.name, func(t *testing.T) {
+			events := DiffTracking(tt.prev, tt.next)
+			if len(events) != len(tt.expect) {
+				t.Fatalf("expected %d events, got %d", len(tt.expect), len(events))
+			}
+			for i, want := range tt.expect {
+				if events[i].Type != want {
+					t.Errorf("event %d type = %q, want %q", i, events[i].Type, want)
+				}
+			}
+		})
+	}
+}
@@ -1,17 +1,25 @@
 package bridge
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/chamdom/omc-agent-tui/internal/codec"
 	"github.com/chamdom/omc-agent-tui/pkg/schema"
 )
 
 // EmitEvent appends a single CanonicalEvent as JSONL to the session file.
 // File path: <eventDir>/<sessionID>.jsonl
+//
+// Many hooks append to one file in parallel, and a torn line would make
+// the file unreadable for replay. Each line is written with one append
+// under an exclusive lock: flock, or where flock is unavailable an O_EXCL
+// lock file beside the event file.
 func EmitEvent(eventDir, sessionID string, event schema.CanonicalEvent) error {
 	if err := os.MkdirAll(eventDir, 0755); err != nil {
 		return fmt.Errorf("create event dir: %w", err)
@@ -31,6 +39,11 @@ func EmitEvent(eventDir, sessionID string, event schema.CanonicalEvent) error {
 	}
 	defer func() { _ = f.Close() }()
 
+	unlock, err := lockFile(f)
+	if err != nil {
+		return fmt.Errorf("lock event file: %w", err)
+	}
+	defer unlock()
 	if _, err := f.Write(line); err != nil {
 		return fmt.Errorf("write event: %w", err)
 	}
@@ -39,28 +52,40 @@ func EmitEvent(eventDir, sessionID string, event schema.CanonicalEvent) error {
 }
 
 // WriteEventsFile writes a slice of CanonicalEvents as JSONL to the given path.
+// A ".gz" extension writes gzip-compressed JSONL.
 func WriteEventsFile(path string, events []schema.CanonicalEvent) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return fmt.Errorf("create output dir: %w", err)
 	}
 
-	f, err := os.Create(path)
+	f, err := codec.Create(path)
 	if err != nil {
 		return fmt.Errorf("create file: %w", err)
 	}
-	defer func() { _ = f.Close() }()
 
 	enc := json.NewEncoder(f)
 	for _, evt := range events {
 		if err := enc.Encode(evt); err != nil {
+			_ = f.Close()
 			return fmt.Errorf("encode event: %w", err)
 		}
 	}
 
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close file: %w", err)
+	}
 	return nil
 }
 
+// SessionRunID returns the run ID for events of a Claude Code session, so
+// every agent in the session shares one run.
+func SessionRunID(sessionID string) string {
+	return "omc-" + sessionID
+}
+
 // NewSpawnEvent creates a task_spawn CanonicalEvent for hook usage.
+// Like the other factories it defaults RunID to "omc-<agentID>"; callers
+// that know the session replace it with SessionRunID.
 func NewSpawnEvent(agentID, agentType, parentMode string) schema.CanonicalEvent {
 	role := mapAgentTypeToRole(agentType)
 	mode := mapParentMode(parentMode)
@@ -108,6 +133,23 @@ func NewDoneEvent(agentID, agentType, parentMode string) schema.CanonicalEvent {
 	}
 }
 
+// NewCancelEvent creates a state_change CanonicalEvent moving the agent to
+// cancelled.
+func NewCancelEvent(agentID, agentType, parentMode string) schema.CanonicalEvent {
+	role := mapAgentTypeToRole(agentType)
+	mode := mapParentMode(parentMode)
+	return schema.CanonicalEvent{
+		Ts:       time.Now(),
+		RunID:    "omc-" + agentID,
+		Provider: schema.ProviderClaude,
+		Mode:     mode,
+		AgentID:  agentID,
+		Role:     role,
+		State:    schema.StateCancelled,
+		Type:     schema.TypeStateChange,
+	}
+}
+
 // NewErrorEvent creates an error CanonicalEvent.
 func NewErrorEvent(agentID, agentType, parentMode, errMsg string) schema.CanonicalEvent {
 	role := mapAgentTypeToRole(agentType)
@@ -131,3 +173,58 @@ func NewErrorEvent(agentID, agentType, parentMode, errMsg string) schema.Canonic
 		Payload:  payload,
 	}
 }
+
+// NewToolCallEvent creates a tool_call CanonicalEvent.
+// spanID links the call to its tool_result; see NewSpanID.
+func NewToolCallEvent(agentID, agentType, parentMode, toolName, spanID string) schema.CanonicalEvent {
+	role := mapAgentTypeToRole(agentType)
+	mode := mapParentMode(parentMode)
+	var payload json.RawMessage
+	if p, err := json.Marshal(schema.ToolCallPayload{ToolName: toolName}); err == nil {
+		payload = p
+	}
+	return schema.CanonicalEvent{
+		Ts:       time.Now(),
+		RunID:    "omc-" + agentID,
+		Provider: schema.ProviderClaude,
+		Mode:     mode,
+		AgentID:  agentID,
+		Role:     role,
+		State:    schema.StateRunning,
+		Type:     schema.TypeToolCall,
+		SpanID:   spanID,
+		Payload:  payload,
+	}
+}
+
+// NewToolResultEvent creates a tool_result CanonicalEvent.
+// spanID must match the span of the originating tool_call.
+func NewToolResultEvent(agentID, agentType, parentMode, toolName, spanID string, success bool) schema.CanonicalEvent {
+	role := mapAgentTypeToRole(agentType)
+	mode := mapParentMode(parentMode)
+	var payload json.RawMessage
+	if p, err := json.Marshal(schema.ToolResultPayload{ToolName: toolName, Success: success}); err == nil {
+		payload = p
+	}
+	return schema.CanonicalEvent{
+		Ts:       time.Now(),
+		RunID:    "omc-" + agentID,
+		Provider: schema.ProviderClaude,
+		Mode:     mode,
+		AgentID:  agentID,
+		Role:     role,
+		State:    schema.StateRunning,
+		Type:     schema.TypeToolResult,
+		SpanID:   spanID,
+		Payload:  payload,
+	}
+}
+
+// NewSpanID returns a random span identifier for pairing tool events.
+func NewSpanID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("span-%x", time.Now().UnixNano())
+	}
+	return "span-" + hex.EncodeToString(b[:])
+}
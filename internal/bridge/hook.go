@@ -0,0 +1,511 @@
+package bridge
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// MainAgentID is the agent ID hook events of the main Claude Code session
+// are attributed to; sub-agents use their Task name.
+const MainAgentID = "main"
+
+// Claude Code hook event names.
+const (
+	HookPreToolUse       = "PreToolUse"
+	HookPostToolUse      = "PostToolUse"
+	HookSubagentStop     = "SubagentStop"
+	HookStop             = "Stop"
+	HookSessionStart     = "SessionStart"
+	HookSessionEnd       = "SessionEnd"
+	HookUserPromptSubmit = "UserPromptSubmit"
+	HookNotification     = "Notification"
+)
+
+// HookInput is the JSON a Claude Code hook receives on stdin.
+type HookInput struct {
+	HookEventName string          `json:"hook_event_name"`
+	SessionID     string          `json:"session_id"`
+	Cwd           string          `json:"cwd"`
+	AgentID       string          `json:"agent_id,omitempty"`   // set for calls made inside a sub-agent
+	AgentType     string          `json:"agent_type,omitempty"` // set for calls made inside a sub-agent
+	ToolName      string          `json:"tool_name,omitempty"`
+	ToolUseID     string          `json:"tool_use_id,omitempty"`
+	ToolInput     json.RawMessage `json:"tool_input,omitempty"`
+	ToolResponse  json.RawMessage `json:"tool_response,omitempty"`
+	Prompt        string          `json:"prompt,omitempty"`  // UserPromptSubmit
+	Message       string          `json:"message,omitempty"` // Notification
+	Reason        string          `json:"reason,omitempty"`  // SessionEnd
+}
+
+// hookToolInput holds the tool_input fields the bridge reads.
+type hookToolInput struct {
+	SubagentType string `json:"subagent_type"` // Task
+	Name         string `json:"name"`          // Task
+	Description  string `json:"description"`   // Task
+	Status       string `json:"status"`        // TaskUpdate
+	Owner        string `json:"owner"`         // TaskUpdate
+	Type         string `json:"type"`          // SendMessage
+	Recipient    string `json:"recipient"`     // SendMessage
+}
+
+// hookToolResponse holds the tool_response fields that signal a failure.
+type hookToolResponse struct {
+	IsError bool   `json:"is_error"`
+	Error   string `json:"error"`
+}
+
+// hookToolCall is a tool call seen in PreToolUse and waiting for its
+// PostToolUse.
+type hookToolCall struct {
+	SpanID   string    `json:"span_id"`
+	Start    time.Time `json:"start"`
+	Subagent string    `json:"subagent,omitempty"` // agent spawned by a Task call
+}
+
+// hookSession is the state kept between hook calls of one session, since
+// every call runs in a new process. It remembers each agent's type so
+// later events for the agent carry its real role, each agent's last state
+// so only real transitions are emitted, which agent spawned each
+// sub-agent and for which task, and open tool calls so results can be
+// paired with their call and timed.
+type hookSession struct {
+	Agents    map[string]string            `json:"agents"`               // agent ID -> agent type
+	States    map[string]schema.AgentState `json:"states,omitempty"`     // agent ID -> last state
+	Parents   map[string]string            `json:"parents,omitempty"`    // agent ID -> spawning agent
+	Tasks     map[string]string            `json:"tasks,omitempty"`      // agent ID -> task ID
+	TaskCount int                          `json:"task_count,omitempty"` // tasks assigned so far
+	Tools     map[string]hookToolCall      `json:"tools,omitempty"`      // open calls by tool_use_id
+	Subagents []string                     `json:"subagents,omitempty"`  // in spawn order
+
+	runID  string
+	now    time.Time
+	events []schema.CanonicalEvent
+}
+
+var validSessionID = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// HookEventDir returns the directory hook events for a project are written
+// to: <cwd>/.omc/events.
+func HookEventDir(cwd string) string {
+	return filepath.Join(cwd, ".omc", "events")
+}
+
+// HookStateDir returns the directory hook state for a project is kept in:
+// <cwd>/.omc/state/hooks. It is outside the event directory so watchers
+// of that directory only ever see event files.
+func HookStateDir(cwd string) string {
+	return filepath.Join(cwd, ".omc", "state", "hooks")
+}
+
+// HookSessionPath returns the sidecar file hook state for a session is
+// kept in.
+func HookSessionPath(stateDir, sessionID string) string {
+	return filepath.Join(stateDir, sessionID+".json")
+}
+
+// lockSession takes the exclusive lock serializing hook calls of a
+// session and returns the function releasing it.
+func lockSession(stateDir, sessionID string) (func(), error) {
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return nil, fmt.Errorf("create hook state dir: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(stateDir, sessionID+".lock"), os.O_CREATE|os.O_RDWR, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("open hook lock: %w", err)
+	}
+	unlock, err := lockFile(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("lock hook session: %w", err)
+	}
+	return func() {
+		unlock()
+		_ = f.Close()
+	}, nil
+}
+
+// HandleHook reads one hook call from r and appends the resulting events
+// to <cwd>/.omc/events/<session_id>.jsonl. Input without a session or
+// working directory, or for an unknown hook event, is ignored. Input
+// without hook_event_name is treated as PostToolUse.
+func HandleHook(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read hook input: %w", err)
+	}
+	var in HookInput
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil
+	}
+	if in.HookEventName == "" && in.ToolName != "" {
+		in.HookEventName = HookPostToolUse
+	}
+	if in.SessionID == "" || in.Cwd == "" {
+		return nil
+	}
+
+	sessionID := filepath.Base(in.SessionID)
+	if !validSessionID.MatchString(sessionID) {
+		return fmt.Errorf("invalid session_id %q", in.SessionID)
+	}
+	eventDir := HookEventDir(in.Cwd)
+	stateDir := HookStateDir(in.Cwd)
+
+	// Hooks of one session run in parallel; hold the session lock from
+	// reading the sidecar until it is saved so no update is lost
+	unlock, err := lockSession(stateDir, sessionID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	session, err := loadHookSession(HookSessionPath(stateDir, sessionID))
+	if err != nil {
+		return err
+	}
+	session.runID = SessionRunID(sessionID)
+	session.handle(in, time.Now())
+	if len(session.events) == 0 {
+		return nil
+	}
+	for _, event := range session.events {
+		if err := EmitEvent(eventDir, sessionID, event); err != nil {
+			return err
+		}
+	}
+	return session.save(HookSessionPath(stateDir, sessionID))
+}
+
+// handle maps one hook call to events, collected in s.events.
+func (s *hookSession) handle(in HookInput, now time.Time) {
+	s.now = now
+
+	switch in.HookEventName {
+	case HookSessionStart:
+		if state, ok := s.States[MainAgentID]; !ok || state.IsTerminal() {
+			s.emit(NewSpawnEvent(MainAgentID, "", ""))
+		}
+
+	case HookUserPromptSubmit:
+		s.ensureMain()
+		s.emit(s.messageEvent(MainAgentID, schema.StateRunning, map[string]any{
+			"kind": "user_prompt", "length": len(in.Prompt),
+		}))
+
+	case HookNotification:
+		s.ensureMain()
+		s.emit(s.messageEvent(MainAgentID, schema.StateWaiting, map[string]any{
+			"kind": "notification", "message": in.Message,
+		}))
+
+	case HookStop:
+		s.transition(MainAgentID, schema.StateWaiting, "stop")
+
+	case HookSubagentStop:
+		// Without agent_id the stopping sub-agent is only known when a
+		// single one is running; otherwise PostToolUse(Task) ends it
+		agentID := s.onlyRunningSubagent()
+		if in.AgentID != "" {
+			agentID = s.caller(in)
+		}
+		if agentID != "" && !s.States[agentID].IsTerminal() {
+			s.emit(NewDoneEvent(agentID, s.Agents[agentID], ""))
+		}
+
+	case HookSessionEnd:
+		for _, agentID := range s.Subagents {
+			if state, ok := s.States[agentID]; ok && !state.IsTerminal() {
+				s.emit(NewCancelEvent(agentID, s.Agents[agentID], ""))
+			}
+		}
+		s.transition(MainAgentID, schema.StateDone, in.Reason)
+
+	case HookPreToolUse:
+		s.preToolUse(in)
+
+	case HookPostToolUse:
+		s.postToolUse(in)
+	}
+}
+
+// preToolUse emits a tool_call and opens a span for its result. A Task
+// call also spawns its sub-agent.
+func (s *hookSession) preToolUse(in HookInput) {
+	if in.ToolName == "" {
+		return
+	}
+	agentID := s.caller(in)
+	call := hookToolCall{SpanID: NewSpanID(), Start: s.now}
+	s.emit(NewToolCallEvent(agentID, s.Agents[agentID], "", in.ToolName, call.SpanID))
+
+	if in.ToolName == "Task" {
+		call.Subagent = s.spawnSubagent(in, agentID)
+	}
+	if s.Tools == nil {
+		s.Tools = make(map[string]hookToolCall)
+	}
+	s.Tools[toolKey(in, agentID)] = call
+}
+
+// postToolUse emits the tool_result for a call, timed from its
+// PreToolUse when one was seen, then any tool-specific lifecycle events.
+func (s *hookSession) postToolUse(in HookInput) {
+	if in.ToolName == "" {
+		return
+	}
+	agentID := s.caller(in)
+	key := toolKey(in, agentID)
+	call, seen := s.Tools[key]
+	delete(s.Tools, key)
+	if !seen {
+		call.SpanID = NewSpanID()
+	}
+
+	msg, failed := toolFailure(in.ToolResponse)
+	result := NewToolResultEvent(agentID, s.Agents[agentID], "", in.ToolName, call.SpanID, !failed)
+	if seen {
+		latency := float64(s.now.Sub(call.Start)) / float64(time.Millisecond)
+		result.Metrics = &schema.EventMetrics{LatencyMs: &latency}
+	}
+	s.emit(result)
+
+	var input hookToolInput
+	if len(in.ToolInput) > 0 {
+		_ = json.Unmarshal(in.ToolInput, &input)
+	}
+
+	switch in.ToolName {
+	case "Task":
+		subagent := call.Subagent
+		if subagent == "" {
+			subagent = s.spawnSubagent(in, agentID)
+		}
+		if s.States[subagent].IsTerminal() {
+			break
+		}
+		if failed {
+			s.emit(NewErrorEvent(subagent, s.Agents[subagent], "", msg))
+		} else {
+			s.emit(NewDoneEvent(subagent, s.Agents[subagent], ""))
+		}
+
+	case "TaskUpdate":
+		owner := orUnknown(input.Owner)
+		switch input.Status {
+		case "completed":
+			s.emit(NewDoneEvent(owner, s.Agents[owner], ""))
+		case "in_progress":
+			s.emit(NewUpdateEvent(owner, s.Agents[owner], "", schema.StateRunning))
+		}
+
+	case "SendMessage":
+		if input.Type == "shutdown_request" {
+			recipient := orUnknown(input.Recipient)
+			s.emit(NewCancelEvent(recipient, s.Agents[recipient], ""))
+		}
+	}
+}
+
+// spawnSubagent emits the task_spawn for a Task call made by parent and
+// returns the new agent's ID: its name, or a hash of the tool input if it
+// has none. Each spawn is a new task, numbered task-1, task-2, ... within
+// the session.
+func (s *hookSession) spawnSubagent(in HookInput, parent string) string {
+	var input hookToolInput
+	if len(in.ToolInput) > 0 {
+		_ = json.Unmarshal(in.ToolInput, &input)
+	}
+	agentType := input.SubagentType
+	if agentType == "" {
+		agentType = string(schema.RoleCustom)
+	}
+	agentID := input.Name
+	if agentID == "" {
+		sum := md5.Sum(in.ToolInput)
+		agentID = "agent-" + hex.EncodeToString(sum[:])[:7]
+	}
+
+	if s.Parents == nil {
+		s.Parents = make(map[string]string)
+	}
+	if s.Tasks == nil {
+		s.Tasks = make(map[string]string)
+	}
+	s.TaskCount++
+	s.Agents[agentID] = agentType
+	s.Parents[agentID] = parent
+	s.Tasks[agentID] = fmt.Sprintf("task-%d", s.TaskCount)
+	s.Subagents = append(s.Subagents, agentID)
+
+	event := NewSpawnEvent(agentID, agentType, "")
+	if payload, err := json.Marshal(schema.TaskSpawnPayload{Title: input.Description, ChildAgent: agentID}); err == nil {
+		event.Payload = payload
+	}
+	s.emit(event)
+	return agentID
+}
+
+// caller returns the agent a tool call belongs to, spawning the main
+// agent on first use.
+func (s *hookSession) caller(in HookInput) string {
+	if in.AgentID == "" {
+		s.ensureMain()
+		return MainAgentID
+	}
+	if in.AgentType != "" {
+		if _, known := s.Agents[in.AgentID]; !known {
+			s.Agents[in.AgentID] = in.AgentType
+		}
+	}
+	return in.AgentID
+}
+
+// ensureMain spawns the main agent if the session has not seen it yet,
+// as when the bridge is installed mid-session.
+func (s *hookSession) ensureMain() {
+	if _, ok := s.States[MainAgentID]; !ok {
+		s.emit(NewSpawnEvent(MainAgentID, "", ""))
+	}
+}
+
+// transition emits a state_change for agentID unless it is already in
+// state or has not been seen.
+func (s *hookSession) transition(agentID string, state schema.AgentState, trigger string) {
+	current, ok := s.States[agentID]
+	if !ok || current == state {
+		return
+	}
+	event := NewUpdateEvent(agentID, s.Agents[agentID], "", state)
+	event.Type = schema.TypeStateChange
+	if payload, err := json.Marshal(schema.StateChangePayload{From: current, To: state, Trigger: trigger}); err == nil {
+		event.Payload = payload
+	}
+	s.emit(event)
+}
+
+// messageEvent creates a message event for agentID with a JSON payload.
+func (s *hookSession) messageEvent(agentID string, state schema.AgentState, payload map[string]any) schema.CanonicalEvent {
+	event := NewUpdateEvent(agentID, s.Agents[agentID], "", state)
+	event.Type = schema.TypeMessage
+	if p, err := json.Marshal(payload); err == nil {
+		event.Payload = p
+	}
+	return event
+}
+
+// onlyRunningSubagent returns the sub-agent that has not ended, or "" if
+// there is none or more than one.
+func (s *hookSession) onlyRunningSubagent() string {
+	running := ""
+	for _, agentID := range s.Subagents {
+		if s.States[agentID].IsTerminal() {
+			continue
+		}
+		if running != "" {
+			return ""
+		}
+		running = agentID
+	}
+	return running
+}
+
+// emit queues event, stamped with the hook's time, the session's run and
+// the agent's parent and task, and records the agent's new state.
+func (s *hookSession) emit(event schema.CanonicalEvent) {
+	event.Ts = s.now
+	if s.runID != "" {
+		event.RunID = s.runID
+	}
+	event.ParentAgentID = s.Parents[event.AgentID]
+	event.TaskID = s.Tasks[event.AgentID]
+	if s.States == nil {
+		s.States = make(map[string]schema.AgentState)
+	}
+	s.States[event.AgentID] = event.State
+	s.events = append(s.events, event)
+}
+
+// toolKey identifies a tool call across its Pre and PostToolUse hooks.
+func toolKey(in HookInput, agentID string) string {
+	if in.ToolUseID != "" {
+		return in.ToolUseID
+	}
+	return agentID + "/" + in.ToolName
+}
+
+// toolFailure reports whether a tool_response describes a failed call.
+func toolFailure(response json.RawMessage) (string, bool) {
+	if len(response) == 0 {
+		return "", false
+	}
+	var resp hookToolResponse
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return "", false
+	}
+	return resp.Error, resp.IsError || resp.Error != ""
+}
+
+// orUnknown returns id, or "unknown" if it is empty.
+func orUnknown(id string) string {
+	if id == "" {
+		return "unknown"
+	}
+	return id
+}
+
+// loadHookSession reads the session sidecar, starting empty if it does not
+// exist yet.
+func loadHookSession(path string) (*hookSession, error) {
+	s := &hookSession{Agents: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read hook session: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		// A corrupt sidecar only costs the remembered state
+		return &hookSession{Agents: make(map[string]string)}, nil
+	}
+	if s.Agents == nil {
+		s.Agents = make(map[string]string)
+	}
+	return s, nil
+}
+
+// save writes the session sidecar atomically.
+func (s *hookSession) save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("encode hook session: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("write hook session: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write hook session: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write hook session: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write hook session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chamdom/omc-agent-tui/internal/store"
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// runHook feeds one hook call for session "sess" in cwd to HandleHook.
+// fields are extra JSON members, e.g. `"tool_name":"Read"`; an empty
+// hookEvent leaves hook_event_name out.
+func runHook(t *testing.T, cwd, hookEvent, fields string) {
+	t.Helper()
+	input := `{"session_id":"sess","cwd":"` + cwd + `"`
+	if hookEvent != "" {
+		input += `,"hook_event_name":"` + hookEvent + `"`
+	}
+	if fields != "" {
+		input += "," + fields
+	}
+	input += "}"
+	if err := HandleHook(strings.NewReader(input)); err != nil {
+		t.Fatalf("HandleHook(%s) error: %v", input, err)
+	}
+}
+
+// readHookEvents returns the events written for session "sess" in cwd.
+func readHookEvents(t *testing.T, cwd string) []schema.CanonicalEvent {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(HookEventDir(cwd), "sess.jsonl"))
+	if err != nil {
+		t.Fatalf("read events: %v", err)
+	}
+	var events []schema.CanonicalEvent
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var evt schema.CanonicalEvent
+		if err := json.Unmarshal([]byte(line), &evt); err != nil {
+			t.Fatalf("unmarshal %q: %v", line, err)
+		}
+		if err := evt.Validate(); err != nil {
+			t.Errorf("invalid event %q: %v", line, err)
+		}
+		events = append(events, evt)
+	}
+	return events
+}
+
+type wantEvent struct {
+	agent string
+	typ   schema.EventType
+	state schema.AgentState
+}
+
+func checkEvents(t *testing.T, events []schema.CanonicalEvent, want []wantEvent) {
+	t.Helper()
+	if len(events) != len(want) {
+		for i, evt := range events {
+			t.Logf("event %d: %s %s %s", i, evt.AgentID, evt.Type, evt.State)
+		}
+		t.Fatalf("expected %d events, got %d", len(want), len(events))
+	}
+	for i, w := range want {
+		evt := events[i]
+		if evt.AgentID != w.agent || evt.Type != w.typ || evt.State != w.state {
+			t.Errorf("event %d = %s %s %s, want %s %s %s",
+				i, evt.AgentID, evt.Type, evt.State, w.agent, w.typ, w.state)
+		}
+	}
+}
+
+func TestHandleHook_Session(t *testing.T) {
+	cwd := t.TempDir()
+	task := `"tool_name":"Task","tool_use_id":"t1","tool_input":{"subagent_type":"oh-my-claudecode:executor","name":"worker-1"}`
+	read := `"agent_id":"worker-1","tool_name":"Read","tool_use_id":"t2","tool_input":{"file_path":"x"}`
+
+	runHook(t, cwd, HookSessionStart, `"source":"startup"`)
+	runHook(t, cwd, HookUserPromptSubmit, `"prompt":"fix the tests"`)
+	runHook(t, cwd, HookPreToolUse, task)
+	runHook(t, cwd, HookPreToolUse, read)
+	runHook(t, cwd, HookPostToolUse, read+`,"tool_response":{"content":"ok"}`)
+	runHook(t, cwd, HookNotification, `"message":"Claude needs your permission"`)
+	runHook(t, cwd, HookSubagentStop, `"stop_hook_active":false`)
+	runHook(t, cwd, HookPostToolUse, task+`,"tool_response":{"content":"done"}`)
+	runHook(t, cwd, HookStop, `"stop_hook_active":false`)
+	runHook(t, cwd, HookSessionEnd, `"reason":"exit"`)
+
+	events := readHookEvents(t, cwd)
+	checkEvents(t, events, []wantEvent{
+		{MainAgentID, schema.TypeTaskSpawn, schema.StateRunning},
+		{MainAgentID, schema.TypeMessage, schema.StateRunning},
+		{MainAgentID, schema.TypeToolCall, schema.StateRunning},
+		{"worker-1", schema.TypeTaskSpawn, schema.StateRunning},
+		{"worker-1", schema.TypeToolCall, schema.StateRunning},
+		{"worker-1", schema.TypeToolResult, schema.StateRunning},
+		{MainAgentID, schema.TypeMessage, schema.StateWaiting},
+		{"worker-1", schema.TypeTaskDone, schema.StateDone},
+		{MainAgentID, schema.TypeToolResult, schema.StateRunning},
+		{MainAgentID, schema.TypeStateChange, schema.StateWaiting},
+		{MainAgentID, schema.TypeStateChange, schema.StateDone},
+	})
+
+	// The sub-agent's role comes from its spawn
+	if events[5].Role != schema.RoleExecutor || events[7].Role != schema.RoleExecutor {
+		t.Errorf("worker roles = %s/%s, want executor", events[5].Role, events[7].Role)
+	}
+
+	// Results share their call's span and are timed from it
+	if events[4].SpanID == "" || events[5].SpanID != events[4].SpanID {
+		t.Errorf("read spans = %q/%q, want matching", events[4].SpanID, events[5].SpanID)
+	}
+	if events[8].SpanID != events[2].SpanID {
+		t.Errorf("task spans = %q/%q, want matching", events[2].SpanID, events[8].SpanID)
+	}
+	if m := events[5].Metrics; m == nil || m.LatencyMs == nil || *m.LatencyMs < 0 {
+		t.Errorf("tool_result metrics = %+v, want a latency", m)
+	}
+	var result schema.ToolResultPayload
+	if err := json.Unmarshal(events[5].Payload, &result); err != nil || result.ToolName != "Read" || !result.Success {
+		t.Errorf("tool_result payload = %s, want Read/success", events[5].Payload)
+	}
+
+	// The prompt itself is not recorded
+	if strings.Contains(string(events[1].Payload), "fix the tests") {
+		t.Errorf("prompt text leaked into payload: %s", events[1].Payload)
+	}
+
+	// Hook state stays out of the watched event directory
+	entries, err := os.ReadDir(HookEventDir(cwd))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "sess.jsonl" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("event dir = %v, want only sess.jsonl", names)
+	}
+	if _, err := os.Stat(HookSessionPath(HookStateDir(cwd), "sess")); err != nil {
+		t.Errorf("session state not saved: %v", err)
+	}
+}
+
+func TestHandleHook_ParallelSubagentStop(t *testing.T) {
+	cwd := t.TempDir()
+	task1 := `"tool_name":"Task","tool_use_id":"t1","tool_input":{"subagent_type":"oh-my-claudecode:executor","name":"worker-1"}`
+	task2 := `"tool_name":"Task","tool_use_id":"t2","tool_input":{"subagent_type":"oh-my-claudecode:executor","name":"worker-2"}`
+
+	runHook(t, cwd, HookPreToolUse, task1)
+	runHook(t, cwd, HookPreToolUse, task2)
+	// Two sub-agents are running, so a stop without agent_id names neither
+	runHook(t, cwd, HookSubagentStop, `"stop_hook_active":false`)
+	runHook(t, cwd, HookPostToolUse, task1+`,"tool_response":{"content":"done"}`)
+	// Now only worker-2 is left
+	runHook(t, cwd, HookSubagentStop, `"stop_hook_active":false`)
+	runHook(t, cwd, HookPostToolUse, task2+`,"tool_response":{"content":"done"}`)
+
+	checkEvents(t, readHookEvents(t, cwd), []wantEvent{
+		{MainAgentID, schema.TypeTaskSpawn, schema.StateRunning},
+		{MainAgentID, schema.TypeToolCall, schema.StateRunning},
+		{"worker-1", schema.TypeTaskSpawn, schema.StateRunning},
+		{MainAgentID, schema.TypeToolCall, schema.StateRunning},
+		{"worker-2", schema.TypeTaskSpawn, schema.StateRunning},
+		{MainAgentID, schema.TypeToolResult, schema.StateRunning},
+		{"worker-1", schema.TypeTaskDone, schema.StateDone},
+		{"worker-2", schema.TypeTaskDone, schema.StateDone},
+		{MainAgentID, schema.TypeToolResult, schema.StateRunning},
+	})
+}
+
+func TestHandleHook_PostToolUseOnly(t *testing.T) {
+	cwd := t.TempDir()
+	runHook(t, cwd, "", `"tool_name":"Task","tool_input":{"subagent_type":"oh-my-claudecode:executor","name":"worker-1"}`)
+	runHook(t, cwd, "", `"tool_name":"TaskUpdate","tool_input":{"status":"in_progress","owner":"worker-1"}`)
+	runHook(t, cwd, "", `"tool_name":"SendMessage","tool_input":{"type":"shutdown_request","recipient":"worker-1"}`)
+
+	events := readHookEvents(t, cwd)
+	checkEvents(t, events, []wantEvent{
+		{MainAgentID, schema.TypeTaskSpawn, schema.StateRunning},
+		{MainAgentID, schema.TypeToolResult, schema.StateRunning},
+		{"worker-1", schema.TypeTaskSpawn, schema.StateRunning},
+		{"worker-1", schema.TypeTaskDone, schema.StateDone},
+		{MainAgentID, schema.TypeToolResult, schema.StateRunning},
+		{"worker-1", schema.TypeTaskUpdate, schema.StateRunning},
+		{MainAgentID, schema.TypeToolResult, schema.StateRunning},
+		{"worker-1", schema.TypeStateChange, schema.StateCancelled},
+	})
+	for _, i := range []int{2, 3, 5, 7} {
+		if events[i].Role != schema.RoleExecutor {
+			t.Errorf("event %d role = %s, want executor", i, events[i].Role)
+		}
+	}
+}
+
+func TestHandleHook_UnnamedAgentAndFailure(t *testing.T) {
+	cwd := t.TempDir()
+	runHook(t, cwd, HookPostToolUse,
+		`"tool_name":"Task","tool_input":{"subagent_type":"debugger"},"tool_response":{"is_error":true,"error":"boom"}`)
+
+	events := readHookEvents(t, cwd)
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+	checkEvents(t, events, []wantEvent{
+		{MainAgentID, schema.TypeTaskSpawn, schema.StateRunning},
+		{MainAgentID, schema.TypeToolResult, schema.StateRunning},
+		{events[2].AgentID, schema.TypeTaskSpawn, schema.StateRunning},
+		{events[2].AgentID, schema.TypeError, schema.StateError},
+	})
+	if !strings.HasPrefix(events[2].AgentID, "agent-") || events[3].Role != schema.RoleDebugger {
+		t.Errorf("sub-agent = %s/%s, want agent-<hash>/debugger", events[2].AgentID, events[3].Role)
+	}
+
+	var result schema.ToolResultPayload
+	if err := json.Unmarshal(events[1].Payload, &result); err != nil || result.Success {
+		t.Errorf("tool_result payload = %s, want failure", events[1].Payload)
+	}
+}
+
+func TestHandleHook_IgnoresAndRejects(t *testing.T) {
+	cwd := t.TempDir()
+	for _, input := range []string{
+		"not json",
+		`{"session_id":"sess","cwd":"` + cwd + `"}`,
+		`{"hook_event_name":"Stop","session_id":"sess","cwd":"` + cwd + `"}`,
+		`{"hook_event_name":"PreCompact","session_id":"sess","cwd":"` + cwd + `"}`,
+		`{"tool_name":"Task","cwd":"` + cwd + `"}`,
+	} {
+		if err := HandleHook(strings.NewReader(input)); err != nil {
+			t.Errorf("HandleHook(%q) error: %v", input, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(HookEventDir(cwd), "sess.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("ignored input should emit nothing, stat err = %v", err)
+	}
+
+	bad := `{"session_id":"a b","cwd":"` + cwd + `","tool_name":"Task","tool_input":{}}`
+	if err := HandleHook(strings.NewReader(bad)); err == nil {
+		t.Error("expected an error for an unsafe session_id")
+	}
+}
+
+func TestHandleHook_Linkage(t *testing.T) {
+	cwd := t.TempDir()
+	runHook(t, cwd, HookSessionStart, "")
+	runHook(t, cwd, HookPreToolUse,
+		`"tool_name":"Task","tool_use_id":"t1","tool_input":{"subagent_type":"planner","name":"lead","description":"Plan the fix"}`)
+	runHook(t, cwd, HookPreToolUse,
+		`"agent_id":"lead","tool_name":"Task","tool_use_id":"t2","tool_input":{"subagent_type":"executor","name":"worker"}`)
+	runHook(t, cwd, HookPostToolUse,
+		`"agent_id":"lead","tool_name":"Task","tool_use_id":"t2","tool_input":{"subagent_type":"executor","name":"worker"}`)
+
+	events := readHookEvents(t, cwd)
+	for i, evt := range events {
+		if evt.RunID != "omc-sess" {
+			t.Errorf("event %d run = %q, want omc-sess", i, evt.RunID)
+		}
+	}
+
+	s := store.NewStore(100)
+	for _, evt := range events {
+		s.AddEvent(evt)
+	}
+	if got := s.GetAgent("lead").ParentAgentID; got != MainAgentID {
+		t.Errorf("lead parent = %q, want main", got)
+	}
+	if got := s.GetAgent("worker").ParentAgentID; got != "lead" {
+		t.Errorf("worker parent = %q, want lead", got)
+	}
+	lead, worker := s.GetTask("task-1"), s.GetTask("task-2")
+	if lead == nil || lead.AgentID != "lead" || lead.Title != "Plan the fix" {
+		t.Fatalf("task-1 = %+v, want lead's task titled Plan the fix", lead)
+	}
+	if worker == nil || worker.ParentTaskID != "task-1" || worker.State != store.TaskDone {
+		t.Errorf("task-2 = %+v, want done under task-1", worker)
+	}
+}
+
+func TestHandleHook_ConcurrentCalls(t *testing.T) {
+	cwd := t.TempDir()
+
+	// Parallel tool calls of one session, each hook its own "process"
+	const calls = 200
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fields := fmt.Sprintf(`"tool_name":"Read","tool_use_id":"t%d","tool_input":{"file_path":"f%d"}`, i, i)
+			for _, hook := range []string{HookPreToolUse, HookPostToolUse} {
+				input := `{"session_id":"sess","cwd":"` + cwd + `","hook_event_name":"` + hook + `",` + fields + `}`
+				if err := HandleHook(strings.NewReader(input)); err != nil {
+					t.Errorf("HandleHook error: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	events := readHookEvents(t, cwd)
+	if len(events) != 1+2*calls {
+		t.Fatalf("expected %d events, got %d", 1+2*calls, len(events))
+	}
+	spawns, timed := 0, 0
+	for _, evt := range events {
+		switch evt.Type {
+		case schema.TypeTaskSpawn:
+			spawns++
+		case schema.TypeToolResult:
+			if evt.Metrics != nil && evt.Metrics.LatencyMs != nil {
+				timed++
+			}
+		}
+	}
+	// Lost sidecar updates would respawn main or leave results unpaired
+	if spawns != 1 || timed != calls {
+		t.Errorf("spawns = %d, timed results = %d; want 1 and %d", spawns, timed, calls)
+	}
+}
@@ -0,0 +1,40 @@
+package bridge
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// exclRetry is how long lockExcl waits before retrying a held lock.
+	exclRetry = 5 * time.Millisecond
+
+	// exclStale is the age after which a lock file is taken to be left
+	// behind by a crashed holder and removed. Hook calls hold their locks
+	// for milliseconds.
+	exclStale = 30 * time.Second
+)
+
+// lockExcl takes an exclusive lock by creating path with O_EXCL, retrying
+// while another holder has it. It is the fallback where flock is
+// unavailable, and works on any platform. The returned function releases
+// the lock by removing path.
+func lockExcl(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("create lock file: %w", err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > exclStale {
+			_ = os.Remove(path)
+			continue
+		}
+		time.Sleep(exclRetry)
+	}
+}
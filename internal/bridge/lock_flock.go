@@ -0,0 +1,24 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package bridge
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive advisory lock on f, blocking until other
+// holders release it. The returned function releases it; closing f
+// releases it too.
+func lockFile(f *os.File) (func(), error) {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err == nil {
+			return func() { _ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN) }, nil
+		}
+		if !errors.Is(err, syscall.EINTR) {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package bridge
+
+import "os"
+
+// lockFile takes an exclusive lock on f where flock is unavailable: an
+// O_EXCL lock file named after f with a ".lock" suffix, see lockExcl. The
+// returned function releases it and must be called before f is closed.
+func lockFile(f *os.File) (func(), error) {
+	return lockExcl(f.Name() + ".lock")
+}
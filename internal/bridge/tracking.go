@@ -3,6 +3,7 @@ package bridge
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"strings"
 	"time"
@@ -62,6 +63,42 @@ func ConvertAgents(agents []TrackedAgent) ([]schema.CanonicalEvent, error) {
 	return events, nil
 }
 
+// DiffTracking returns the events for what changed between two versions
+// of a tracking file's agents: spawn events for agents new in next, and
+// terminal events for agents that have ended since prev. Agents missing
+// from next are ignored, and an agent with unparseable timestamps is
+// logged and skipped so the rest of the file still shows up live.
+func DiffTracking(prev, next []TrackedAgent) []schema.CanonicalEvent {
+	ended := make(map[string]bool, len(prev))
+	seen := make(map[string]bool, len(prev))
+	for _, a := range prev {
+		seen[a.AgentID] = true
+		ended[a.AgentID] = hasEnded(a)
+	}
+
+	var events []schema.CanonicalEvent
+	for _, a := range next {
+		if seen[a.AgentID] && (ended[a.AgentID] || !hasEnded(a)) {
+			continue
+		}
+		evts, err := agentToEvents(a)
+		if err != nil {
+			log.Printf("[WARN] skip tracked agent %s: %v", a.AgentID, err)
+			continue
+		}
+		if seen[a.AgentID] {
+			evts = evts[1:] // spawned in an earlier version
+		}
+		events = append(events, evts...)
+	}
+	return events
+}
+
+// hasEnded reports whether agentToEvents emits a terminal event for a.
+func hasEnded(a TrackedAgent) bool {
+	return a.Status != "running" && a.CompletedAt != ""
+}
+
 func agentToEvents(a TrackedAgent) ([]schema.CanonicalEvent, error) {
 	startedAt, err := time.Parse(time.RFC3339Nano, a.StartedAt)
 	if err != nil {
@@ -86,7 +123,7 @@ func agentToEvents(a TrackedAgent) ([]schema.CanonicalEvent, error) {
 	events := []schema.CanonicalEvent{spawn}
 
 	// Terminal event (if not still running)
-	if a.Status != "running" && a.CompletedAt != "" {
+	if hasEnded(a) {
 		completedAt, err := time.Parse(time.RFC3339Nano, a.CompletedAt)
 		if err != nil {
 			return nil, fmt.Errorf("parse completed_at: %w", err)
@@ -0,0 +1,107 @@
+// Package codec selects the on-disk encoding of event files by extension.
+// Plain ".jsonl" files are read and written as-is; a trailing ".gz"
+// (e.g. "session.jsonl.gz") adds gzip compression. Callers keep working
+// with JSONL lines either way.
+package codec
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExtGzip marks a gzip-compressed JSONL file.
+const ExtGzip = ".gz"
+
+// IsCompressed reports whether path uses a compressed encoding.
+func IsCompressed(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ExtGzip)
+}
+
+// IsEventFile reports whether path looks like a JSONL event file in any
+// supported encoding.
+func IsEventFile(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	return strings.HasSuffix(name, ".jsonl") || strings.HasSuffix(name, ".jsonl"+ExtGzip)
+}
+
+// IsTruncated reports whether err means the compressed stream ended
+// mid-member, which happens while a writer is still flushing.
+func IsTruncated(err error) bool {
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Open opens path for reading and decompresses it when needed.
+// The returned reader yields plain JSONL.
+func Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !IsCompressed(path) {
+		return f, nil
+	}
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		if err == io.EOF {
+			// Empty file: nothing written yet.
+			return io.NopCloser(strings.NewReader("")), nil
+		}
+		return nil, fmt.Errorf("open gzip stream: %w", err)
+	}
+	return &readCloser{Reader: zr, closers: []io.Closer{zr, f}}, nil
+}
+
+// Create creates or truncates path for writing. Data written to the
+// returned writer is compressed when the extension requires it; Close
+// must be called to flush the stream.
+func Create(path string) (io.WriteCloser, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if !IsCompressed(path) {
+		return f, nil
+	}
+
+	zw := gzip.NewWriter(f)
+	return &writeCloser{Writer: zw, closers: []io.Closer{zw, f}}, nil
+}
+
+// readCloser closes every layer of a decoding stack, innermost first.
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *readCloser) Close() error {
+	var first error
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// writeCloser flushes and closes every layer of an encoding stack.
+type writeCloser struct {
+	io.Writer
+	closers []io.Closer
+}
+
+func (w *writeCloser) Close() error {
+	var first error
+	for _, c := range w.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,113 @@
+package codec
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsCompressed(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"session.jsonl", false},
+		{"session.jsonl.gz", true},
+		{"SESSION.JSONL.GZ", true},
+		{"notes.txt", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := IsCompressed(tt.path); got != tt.want {
+				t.Errorf("IsCompressed(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsEventFile(t *testing.T) {
+	for path, want := range map[string]bool{
+		"a.jsonl":    true,
+		"a.jsonl.gz": true,
+		"a.json":     false,
+		"a.gz":       false,
+	} {
+		if got := IsEventFile(path); got != want {
+			t.Errorf("IsEventFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestRoundtrip(t *testing.T) {
+	const content = "{\"a\":1}\n{\"b\":2}\n"
+
+	for _, name := range []string{"plain.jsonl", "packed.jsonl.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+
+			w, err := Create(path)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if _, err := io.WriteString(w, content); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+
+			r, err := Open(path)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			defer func() { _ = r.Close() }()
+
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if string(got) != content {
+				t.Errorf("got %q, want %q", got, content)
+			}
+		})
+	}
+}
+
+func TestCreate_CompressesOnDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl.gz")
+	w, err := Create(path)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	_, _ = io.WriteString(w, "{}\n")
+	_ = w.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := gzip.NewReader(f); err != nil {
+		t.Errorf("file should be gzip: %v", err)
+	}
+}
+
+func TestOpen_EmptyCompressedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.jsonl.gz")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	got, _ := io.ReadAll(r)
+	if len(got) != 0 {
+		t.Errorf("expected no data, got %q", got)
+	}
+}
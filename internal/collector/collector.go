@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/chamdom/omc-agent-tui/internal/codec"
 	"github.com/chamdom/omc-agent-tui/pkg/schema"
 	"github.com/fsnotify/fsnotify"
 )
@@ -176,20 +177,45 @@ func (fc *FileCollector) recordSuccess() {
 }
 
 // readNewLines는 파일의 새로운 라인들을 읽어 이벤트로 변환합니다.
+// 이벤트 파일(.jsonl, .jsonl.gz)이 아닌 파일은 읽지 않으며 위치도 기록하지 않습니다.
 func (fc *FileCollector) readNewLines(filePath string, positions map[string]int64) error {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("파일 열기 실패: %w", err)
+	if !codec.IsEventFile(filePath) {
+		return nil
 	}
-	defer func() { _ = file.Close() }()
-
-	// 이전 위치로 이동
 	startPos := positions[filePath]
-	if _, err := file.Seek(startPos, io.SeekStart); err != nil {
-		return fmt.Errorf("파일 위치 이동 실패: %w", err)
+
+	var reader io.Reader
+	if codec.IsCompressed(filePath) {
+		// 압축 파일은 임의 위치 이동이 불가능하므로 처음부터 풀어서
+		// 이미 처리한 (압축 해제 기준) 바이트를 건너뜁니다.
+		rc, err := codec.Open(filePath)
+		if err != nil {
+			return fmt.Errorf("파일 열기 실패: %w", err)
+		}
+		defer func() { _ = rc.Close() }()
+
+		if _, err := io.CopyN(io.Discard, rc, startPos); err != nil {
+			if err == io.EOF || codec.IsTruncated(err) {
+				return nil // 아직 새 데이터가 기록되지 않음
+			}
+			return fmt.Errorf("파일 위치 이동 실패: %w", err)
+		}
+		reader = rc
+	} else {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return fmt.Errorf("파일 열기 실패: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+
+		// 이전 위치로 이동
+		if _, err := file.Seek(startPos, io.SeekStart); err != nil {
+			return fmt.Errorf("파일 위치 이동 실패: %w", err)
+		}
+		reader = file
 	}
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(reader)
 	var bytesProcessed int64
 
 	for scanner.Scan() {
@@ -223,7 +249,8 @@ func (fc *FileCollector) readNewLines(filePath string, positions map[string]int6
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
+	// 압축 스트림이 기록 도중 잘린 경우는 오류가 아니라 다음 WRITE 이벤트에서 이어 읽습니다.
+	if err := scanner.Err(); err != nil && !codec.IsTruncated(err) {
 		return fmt.Errorf("파일 읽기 중 오류: %w", err)
 	}
 
@@ -1,6 +1,7 @@
 package collector
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"os"
@@ -265,3 +266,80 @@ func TestFileCollector_BackoffLevels(t *testing.T) {
 		t.Error("level 1 backoff should be longer than level 0")
 	}
 }
+
+func TestFileCollector_ReadCompressed(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "events.jsonl.gz")
+
+	// 각 flush마다 별도의 gzip member로 이어 붙여 tail-follow를 흉내냅니다.
+	appendMember := func(lines ...string) {
+		f, err := os.OpenFile(testFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		zw := gzip.NewWriter(f)
+		for _, line := range lines {
+			_, _ = zw.Write([]byte(line + "\n"))
+		}
+		_ = zw.Close()
+		_ = f.Close()
+	}
+
+	fc := NewFileCollector(tmpDir)
+	positions := make(map[string]int64)
+
+	appendMember(`{"event":"a"}`, `{"event":"b"}`)
+	if err := fc.readNewLines(testFile, positions); err != nil {
+		t.Fatalf("readNewLines: %v", err)
+	}
+	if got := len(fc.events); got != 2 {
+		t.Fatalf("expected 2 events, got %d", got)
+	}
+
+	appendMember(`{"event":"c"}`)
+	if err := fc.readNewLines(testFile, positions); err != nil {
+		t.Fatalf("readNewLines: %v", err)
+	}
+	if got := len(fc.events); got != 3 {
+		t.Fatalf("expected 3 events after append, got %d", got)
+	}
+}
+
+func TestFileCollector_SkipsNonEventFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fc := NewFileCollector(tmpDir)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := fc.Start(ctx); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer fc.Stop()
+
+	time.Sleep(500 * time.Millisecond)
+
+	// 이벤트 파일이 아닌 파일은 JSONL이어도 무시되어야 함
+	for _, name := range []string{"sess.json", "sess.json.tmp123", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(`{"event":"x"}`+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "events.jsonl"), []byte(`{"event":"a"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := drainEvents(ctx, fc, 1); err != nil {
+		t.Fatalf("timeout: received %d/1 events", got)
+	}
+	select {
+	case raw := <-fc.Events():
+		t.Errorf("unexpected event from a non-event file: %s (%s)", raw.Data, raw.Source)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	positions := make(map[string]int64)
+	_ = fc.readNewLines(filepath.Join(tmpDir, "notes.txt"), positions)
+	if len(positions) != 0 {
+		t.Errorf("non-event file should not be tracked, got %v", positions)
+	}
+}
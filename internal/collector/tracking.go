@@ -0,0 +1,145 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/internal/bridge"
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+	"github.com/fsnotify/fsnotify"
+)
+
+// TrackingCollector는 OMC의 subagent-tracking.json 파일을 감시하는 수집기입니다.
+// 파일이 바뀔 때마다 이전 버전의 Agents와 비교하여 spawn/done/failed
+// 이벤트를 증분으로 내보내므로, hook이 없어도 실시간으로 볼 수 있습니다.
+type TrackingCollector struct {
+	path     string
+	events   chan schema.RawEvent
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+	cancel   context.CancelFunc
+
+	agents []bridge.TrackedAgent // 마지막으로 반영한 버전
+}
+
+// NewTrackingCollector는 path의 tracking 파일을 감시하는 수집기를 생성합니다.
+func NewTrackingCollector(path string) *TrackingCollector {
+	return &TrackingCollector{
+		path:   filepath.Clean(path),
+		events: make(chan schema.RawEvent, 1000),
+	}
+}
+
+// Start는 현재 파일 내용을 내보낸 뒤 감시를 시작합니다.
+// 파일은 교체 방식(rename)으로 쓰일 수 있으므로 상위 디렉터리를 감시합니다.
+func (tc *TrackingCollector) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("fsnotify watcher 생성 실패: %w", err)
+	}
+
+	dir := filepath.Dir(tc.path)
+	if err := watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("경로 감시 추가 실패 (%s): %w", dir, err)
+	}
+
+	// 파일이 아직 없으면 생성될 때 읽음
+	_ = tc.readTracking()
+
+	internalCtx, cancel := context.WithCancel(ctx)
+	tc.cancel = cancel
+
+	tc.wg.Add(1)
+	go func() {
+		defer tc.wg.Done()
+		defer func() { _ = watcher.Close() }()
+
+		for {
+			select {
+			case <-internalCtx.Done():
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != tc.path || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				// 쓰는 도중의 파일은 파싱에 실패하므로 다음 이벤트에서 다시 읽음
+				_ = tc.readTracking()
+
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				// 에러는 무시하고 계속 진행 (장애 격리)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Events는 수집된 이벤트 채널을 반환합니다.
+func (tc *TrackingCollector) Events() <-chan schema.RawEvent {
+	return tc.events
+}
+
+// Stop은 수집기를 중지합니다. 최대 5초 대기 후 강제 종료합니다.
+func (tc *TrackingCollector) Stop() {
+	tc.stopOnce.Do(func() {
+		if tc.cancel != nil {
+			tc.cancel()
+		}
+		done := make(chan struct{})
+		go func() {
+			tc.wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+		}
+		close(tc.events)
+	})
+}
+
+// readTracking은 파일을 읽어 이전 버전과의 차이를 이벤트로 내보냅니다.
+func (tc *TrackingCollector) readTracking() error {
+	data, err := os.ReadFile(tc.path)
+	if err != nil {
+		return fmt.Errorf("파일 읽기 실패: %w", err)
+	}
+	var tf bridge.TrackingFile
+	if err := json.Unmarshal(data, &tf); err != nil {
+		return fmt.Errorf("tracking 파일 파싱 실패: %w", err)
+	}
+
+	events := bridge.DiffTracking(tc.agents, tf.Agents)
+	tc.agents = tf.Agents
+
+	for _, evt := range events {
+		line, err := json.Marshal(evt)
+		if err != nil {
+			continue
+		}
+		raw := schema.RawEvent{
+			Source:   tc.path,
+			Data:     line,
+			Received: time.Now(),
+		}
+		// buffered channel이 가득 찬 경우 이벤트 드롭
+		select {
+		case tc.events <- raw:
+		default:
+		}
+	}
+	return nil
+}
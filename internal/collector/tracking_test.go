@@ -0,0 +1,108 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/internal/bridge"
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// writeTracking atomically replaces the tracking file with agents.
+func writeTracking(t *testing.T, path string, agents ...bridge.TrackedAgent) {
+	t.Helper()
+	data, err := json.Marshal(bridge.TrackingFile{Agents: agents})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// nextEvent decodes the next canonical event sent by tc.
+func nextEvent(ctx context.Context, t *testing.T, tc *TrackingCollector) schema.CanonicalEvent {
+	t.Helper()
+	select {
+	case raw := <-tc.Events():
+		var evt schema.CanonicalEvent
+		if err := json.Unmarshal(raw.Data, &evt); err != nil {
+			t.Fatalf("unmarshal event: %v", err)
+		}
+		return evt
+	case <-ctx.Done():
+		t.Fatal("timeout waiting for tracking event")
+		return schema.CanonicalEvent{}
+	}
+}
+
+func TestTrackingCollector_Incremental(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subagent-tracking.json")
+	a := bridge.TrackedAgent{AgentID: "a1", AgentType: "oh-my-claudecode:executor", StartedAt: "2026-02-17T20:33:41Z", Status: "running"}
+	writeTracking(t, path, a)
+
+	tc := NewTrackingCollector(path)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := tc.Start(ctx); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer tc.Stop()
+
+	// The current contents are sent on start
+	if evt := nextEvent(ctx, t, tc); evt.AgentID != "a1" || evt.Type != schema.TypeTaskSpawn {
+		t.Fatalf("first event = %s %s, want a1 task_spawn", evt.AgentID, evt.Type)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	b := bridge.TrackedAgent{AgentID: "b1", AgentType: "architect", StartedAt: "2026-02-17T20:34:00Z", Status: "running"}
+	a.Status, a.CompletedAt = "completed", "2026-02-17T20:40:00Z"
+	writeTracking(t, path, a, b)
+
+	// Only the changes follow: a1 done and b1 spawned
+	got := map[string]schema.EventType{}
+	for len(got) < 2 {
+		evt := nextEvent(ctx, t, tc)
+		got[evt.AgentID] = evt.Type
+	}
+	if got["a1"] != schema.TypeTaskDone || got["b1"] != schema.TypeTaskSpawn {
+		t.Errorf("events = %v, want a1 task_done and b1 task_spawn", got)
+	}
+
+	select {
+	case raw := <-tc.Events():
+		t.Errorf("unexpected extra event: %s", raw.Data)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestTrackingCollector_SkipsBadAgent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subagent-tracking.json")
+	bad := bridge.TrackedAgent{AgentID: "bad", AgentType: "executor", StartedAt: "not a time", Status: "running"}
+	writeTracking(t, path, bad)
+
+	tc := NewTrackingCollector(path)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := tc.Start(ctx); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer tc.Stop()
+
+	// 잘못된 agent가 있어도 이후 변경은 계속 반영되어야 함
+	time.Sleep(100 * time.Millisecond)
+	good := bridge.TrackedAgent{AgentID: "a1", AgentType: "executor", StartedAt: "2026-02-17T20:33:41Z", Status: "running"}
+	writeTracking(t, path, bad, good)
+
+	if evt := nextEvent(ctx, t, tc); evt.AgentID != "a1" || evt.Type != schema.TypeTaskSpawn {
+		t.Errorf("event = %s %s, want a1 task_spawn", evt.AgentID, evt.Type)
+	}
+}
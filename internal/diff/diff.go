@@ -0,0 +1,347 @@
+// Package diff compares two recorded sessions, typically a regressed run
+// against a known good run of the same task.
+package diff
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/internal/replay"
+	"github.com/chamdom/omc-agent-tui/internal/store"
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// Result is the comparison of session A with session B.
+type Result struct {
+	A, B Summary
+
+	// Divergence is the first position where the event sequences differ;
+	// nil if they match event for event
+	Divergence *Divergence
+
+	OnlyInA []string   // agents spawned only in A
+	OnlyInB []string   // agents spawned only in B
+	Tasks   []TaskDiff // tasks whose outcome differs
+	Roles   []RoleDiff // every role seen in either session
+}
+
+// Summary describes one session.
+type Summary struct {
+	Path     string
+	Events   int
+	Agents   int
+	Duration time.Duration // first to last event
+	CostUSD  float64
+
+	// Skipped lists invalid or truncated lines left out while loading,
+	// e.g. the torn last line of a session that is still being written
+	Skipped []replay.LineError
+}
+
+// Divergence is the first differing pair of events. A or B is nil when
+// that session ended first.
+type Divergence struct {
+	Index int
+	A, B  *schema.CanonicalEvent
+}
+
+// TaskDiff is a task whose final state differs. A state is "" when the
+// task does not exist in that session.
+type TaskDiff struct {
+	TaskID string
+	Title  string
+	StateA string
+	StateB string
+}
+
+// RoleDiff compares the cost and agent time of one role.
+type RoleDiff struct {
+	Role      schema.Role
+	CostA     float64
+	CostB     float64
+	DurationA time.Duration // summed agent uptime
+	DurationB time.Duration
+}
+
+// chunkSize is how many events are read from a session at a time, so
+// large sessions stream from disk instead of being copied into memory.
+const chunkSize = 1024
+
+// session is a loaded session: the player serving its events in playback
+// order and a Store holding their aggregates.
+type session struct {
+	path   string
+	player *replay.Player
+	store  *store.Store
+	start  time.Time // first event Ts
+	last   time.Time // last event Ts
+}
+
+// Compare loads two session files and compares them. Invalid lines are
+// skipped and listed in each Summary's Skipped.
+func Compare(pathA, pathB string) (*Result, error) {
+	a, err := load(pathA)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = a.player.Close() }()
+	b, err := load(pathB)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = b.player.Close() }()
+
+	result := &Result{
+		A:          a.summary(),
+		B:          b.summary(),
+		Divergence: diverge(a.player, b.player),
+	}
+	result.OnlyInA, result.OnlyInB = compareAgents(a.store, b.store)
+	result.Tasks = compareTasks(a.store, b.store)
+	result.Roles = compareRoles(a, b)
+	return result, nil
+}
+
+// load opens path tolerantly and feeds its events, a chunk at a time,
+// into a fresh Store. The Store only keeps aggregates, so its ring holds
+// one chunk.
+func load(path string) (*session, error) {
+	player := replay.NewPlayer()
+	player.SetTolerant(true)
+	if err := player.LoadFile(path); err != nil {
+		return nil, fmt.Errorf("load %s: %w", path, err)
+	}
+
+	s := &session{path: path, player: player, store: store.NewStore(chunkSize)}
+	total := player.Total()
+	for from := 0; from < total; from += chunkSize {
+		for _, event := range player.Events(from, from+chunkSize) {
+			if s.start.IsZero() {
+				s.start = event.Ts
+			}
+			s.last = event.Ts
+			s.store.AddEvent(event)
+		}
+	}
+	return s, nil
+}
+
+// end returns the timestamp of the last event.
+func (s *session) end() time.Time {
+	return s.last
+}
+
+// summary returns the session's headline numbers.
+func (s *session) summary() Summary {
+	return Summary{
+		Path:     s.path,
+		Events:   s.player.Total(),
+		Agents:   len(s.store.GetAllAgents()),
+		Duration: s.last.Sub(s.start),
+		CostUSD:  s.store.GetMetrics().TotalCostUSD,
+		Skipped:  s.player.Report().Skipped,
+	}
+}
+
+// sameStep reports whether two events describe the same step of a run.
+// Timestamps and IDs that differ on every run are ignored.
+func sameStep(a, b schema.CanonicalEvent) bool {
+	return a.AgentID == b.AgentID &&
+		a.Type == b.Type &&
+		a.State == b.State &&
+		a.TaskID == b.TaskID
+}
+
+// diverge finds the first position where the sessions' event sequences
+// differ, reading both a chunk at a time.
+func diverge(a, b *replay.Player) *Divergence {
+	for from := 0; from < a.Total() || from < b.Total(); from += chunkSize {
+		if d := divergeChunk(a.Events(from, from+chunkSize), b.Events(from, from+chunkSize)); d != nil {
+			d.Index += from
+			return d
+		}
+	}
+	return nil
+}
+
+// divergeChunk finds the first position where the sequences differ.
+func divergeChunk(a, b []schema.CanonicalEvent) *Divergence {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		switch {
+		case i >= len(a):
+			return &Divergence{Index: i, B: &b[i]}
+		case i >= len(b):
+			return &Divergence{Index: i, A: &a[i]}
+		case !sameStep(a[i], b[i]):
+			return &Divergence{Index: i, A: &a[i], B: &b[i]}
+		}
+	}
+	return nil
+}
+
+// compareAgents returns the agent IDs seen in only one of the stores.
+func compareAgents(a, b *store.Store) (onlyA, onlyB []string) {
+	for _, agent := range a.GetAllAgents() {
+		if b.GetAgent(agent.AgentID) == nil {
+			onlyA = append(onlyA, agent.AgentID)
+		}
+	}
+	for _, agent := range b.GetAllAgents() {
+		if a.GetAgent(agent.AgentID) == nil {
+			onlyB = append(onlyB, agent.AgentID)
+		}
+	}
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	return onlyA, onlyB
+}
+
+// compareTasks returns the tasks whose final state differs, by task ID.
+func compareTasks(a, b *store.Store) []TaskDiff {
+	diffs := make(map[string]*TaskDiff)
+	for _, task := range a.GetAllTasks() {
+		diffs[task.TaskID] = &TaskDiff{TaskID: task.TaskID, Title: task.Title, StateA: task.State}
+	}
+	for _, task := range b.GetAllTasks() {
+		d, ok := diffs[task.TaskID]
+		if !ok {
+			d = &TaskDiff{TaskID: task.TaskID}
+			diffs[task.TaskID] = d
+		}
+		d.StateB = task.State
+		if d.Title == "" {
+			d.Title = task.Title
+		}
+	}
+
+	var result []TaskDiff
+	for _, d := range diffs {
+		if d.StateA != d.StateB {
+			result = append(result, *d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].TaskID < result[j].TaskID
+	})
+	return result
+}
+
+// compareRoles returns cost and agent time per role, by role name.
+func compareRoles(a, b *session) []RoleDiff {
+	diffs := make(map[schema.Role]*RoleDiff)
+	get := func(role schema.Role) *RoleDiff {
+		if d, ok := diffs[role]; ok {
+			return d
+		}
+		d := &RoleDiff{Role: role}
+		diffs[role] = d
+		return d
+	}
+
+	for role, m := range a.store.GetRoleMetrics() {
+		get(role).CostA = m.TotalCostUSD
+	}
+	for role, m := range b.store.GetRoleMetrics() {
+		get(role).CostB = m.TotalCostUSD
+	}
+	for _, agent := range a.store.GetAllAgents() {
+		get(agent.Role).DurationA += agent.Uptime(a.end())
+	}
+	for _, agent := range b.store.GetAllAgents() {
+		get(agent.Role).DurationB += agent.Uptime(b.end())
+	}
+
+	result := make([]RoleDiff, 0, len(diffs))
+	for _, d := range diffs {
+		result = append(result, *d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Role < result[j].Role
+	})
+	return result
+}
+
+// Write prints the comparison as a side-by-side text report.
+func (r *Result) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "\tA\tB\tDelta\n")
+	fmt.Fprintf(tw, "Session\t%s\t%s\t\n", r.A.Path, r.B.Path)
+	fmt.Fprintf(tw, "Events\t%d\t%d\t%+d\n", r.A.Events, r.B.Events, r.B.Events-r.A.Events)
+	fmt.Fprintf(tw, "Agents\t%d\t%d\t%+d\n", r.A.Agents, r.B.Agents, r.B.Agents-r.A.Agents)
+	fmt.Fprintf(tw, "Duration\t%s\t%s\t%s\n", r.A.Duration, r.B.Duration, signed(r.B.Duration-r.A.Duration))
+	fmt.Fprintf(tw, "Cost\t$%.4f\t$%.4f\t%+.4f\n", r.A.CostUSD, r.B.CostUSD, r.B.CostUSD-r.A.CostUSD)
+
+	fmt.Fprintf(tw, "\nFirst divergence\t\t\t\n")
+	if r.Divergence == nil {
+		fmt.Fprintf(tw, "  none, the sequences match\t\t\t\n")
+	} else {
+		fmt.Fprintf(tw, "  at event %d\t%s\t%s\t\n",
+			r.Divergence.Index, describe(r.Divergence.A), describe(r.Divergence.B))
+	}
+
+	fmt.Fprintf(tw, "\nAgents in one session only\t\t\t\n")
+	if len(r.OnlyInA) == 0 && len(r.OnlyInB) == 0 {
+		fmt.Fprintf(tw, "  none\t\t\t\n")
+	}
+	for _, id := range r.OnlyInA {
+		fmt.Fprintf(tw, "  %s\tspawned\t-\t\n", id)
+	}
+	for _, id := range r.OnlyInB {
+		fmt.Fprintf(tw, "  %s\t-\tspawned\t\n", id)
+	}
+
+	fmt.Fprintf(tw, "\nTask outcomes that differ\t\t\t\n")
+	if len(r.Tasks) == 0 {
+		fmt.Fprintf(tw, "  none\t\t\t\n")
+	}
+	for _, task := range r.Tasks {
+		name := task.TaskID
+		if task.Title != "" && task.Title != task.TaskID {
+			name += " (" + task.Title + ")"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t\n", name, orDash(task.StateA), orDash(task.StateB))
+	}
+
+	fmt.Fprintf(tw, "\nPer role\t\t\t\n")
+	for _, role := range r.Roles {
+		fmt.Fprintf(tw, "  %s cost\t$%.4f\t$%.4f\t%+.4f\n",
+			role.Role, role.CostA, role.CostB, role.CostB-role.CostA)
+		fmt.Fprintf(tw, "  %s agent time\t%s\t%s\t%s\n",
+			role.Role, role.DurationA, role.DurationB, signed(role.DurationB-role.DurationA))
+	}
+
+	return tw.Flush()
+}
+
+// describe summarizes an event for the divergence line.
+func describe(event *schema.CanonicalEvent) string {
+	if event == nil {
+		return "(ended)"
+	}
+	parts := []string{event.AgentID, string(event.Type), string(event.State)}
+	if event.TaskID != "" {
+		parts = append(parts, "task:"+event.TaskID)
+	}
+	return strings.Join(parts, " ")
+}
+
+// signed formats a duration delta with an explicit sign.
+func signed(d time.Duration) string {
+	if d >= 0 {
+		return "+" + d.String()
+	}
+	return d.String()
+}
+
+// orDash returns s, or "-" if it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,192 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+var base = time.Date(2026, 2, 17, 22, 27, 0, 0, time.UTC)
+
+// event builds an event sec seconds into the session.
+func event(sec int, agent string, role schema.Role, state schema.AgentState, typ schema.EventType, task string, cost float64) schema.CanonicalEvent {
+	e := schema.CanonicalEvent{
+		Ts: base.Add(time.Duration(sec) * time.Second), RunID: "run-1", Provider: "claude",
+		AgentID: agent, Role: role, State: state, Type: typ, TaskID: task,
+	}
+	if cost > 0 {
+		e.Metrics = &schema.EventMetrics{CostUSD: &cost}
+	}
+	return e
+}
+
+// writeSession writes events as JSONL to dir/name.
+func writeSession(t *testing.T, dir, name string, events ...schema.CanonicalEvent) string {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatalf("marshal event: %v", err)
+		}
+		buf.Write(append(data, '\n'))
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCompare(t *testing.T) {
+	dir := t.TempDir()
+	good := writeSession(t, dir, "good.jsonl",
+		event(0, "orch", "planner", "running", schema.TypeTaskSpawn, "t1", 0),
+		event(1, "exec-1", "executor", "running", schema.TypeTaskSpawn, "t1", 0),
+		event(5, "exec-1", "executor", "running", schema.TypeMessage, "t1", 0.10),
+		event(9, "exec-1", "executor", "done", schema.TypeTaskDone, "t1", 0),
+	)
+	bad := writeSession(t, dir, "bad.jsonl",
+		event(0, "orch", "planner", "running", schema.TypeTaskSpawn, "t1", 0),
+		event(1, "exec-1", "executor", "running", schema.TypeTaskSpawn, "t1", 0),
+		event(3, "exec-1", "executor", "error", schema.TypeError, "t1", 0.25),
+		event(4, "debug-1", "debugger", "running", schema.TypeMessage, "", 0.05),
+		event(20, "debug-1", "debugger", "done", schema.TypeMessage, "", 0),
+	)
+
+	result, err := Compare(good, bad)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	if result.A.Events != 4 || result.B.Events != 5 {
+		t.Errorf("events = %d/%d, want 4/5", result.A.Events, result.B.Events)
+	}
+	if result.A.Duration != 9*time.Second || result.B.Duration != 20*time.Second {
+		t.Errorf("durations = %s/%s, want 9s/20s", result.A.Duration, result.B.Duration)
+	}
+
+	d := result.Divergence
+	if d == nil || d.Index != 2 || d.A.Type != schema.TypeMessage || d.B.Type != schema.TypeError {
+		t.Fatalf("divergence = %+v, want index 2 message vs error", d)
+	}
+
+	if len(result.OnlyInA) != 0 || len(result.OnlyInB) != 1 || result.OnlyInB[0] != "debug-1" {
+		t.Errorf("only in A %v, only in B %v, want [] and [debug-1]", result.OnlyInA, result.OnlyInB)
+	}
+
+	if len(result.Tasks) != 1 || result.Tasks[0].StateA != "done" || result.Tasks[0].StateB != "failed" {
+		t.Errorf("tasks = %+v, want t1 done vs failed", result.Tasks)
+	}
+
+	roles := make(map[schema.Role]RoleDiff)
+	for _, r := range result.Roles {
+		roles[r.Role] = r
+	}
+	exec := roles["executor"]
+	if exec.CostA != 0.10 || exec.CostB != 0.25 {
+		t.Errorf("executor cost = %v/%v, want 0.10/0.25", exec.CostA, exec.CostB)
+	}
+	// An errored agent is not terminal, so it counts until the session ends
+	if exec.DurationA != 8*time.Second || exec.DurationB != 19*time.Second {
+		t.Errorf("executor time = %s/%s, want 8s/19s", exec.DurationA, exec.DurationB)
+	}
+	if debug, ok := roles["debugger"]; !ok || debug.CostA != 0 || debug.CostB != 0.05 {
+		t.Errorf("debugger = %+v, want cost only in B", debug)
+	}
+
+	var out bytes.Buffer
+	if err := result.Write(&out); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	for _, want := range []string{"at event 2", "debug-1", "failed", "executor cost"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCompare_Identical(t *testing.T) {
+	dir := t.TempDir()
+	events := []schema.CanonicalEvent{
+		event(0, "exec-1", "executor", "running", schema.TypeMessage, "", 0),
+		event(1, "exec-1", "executor", "done", schema.TypeMessage, "", 0),
+	}
+	a := writeSession(t, dir, "a.jsonl", events...)
+	b := writeSession(t, dir, "b.jsonl", events...)
+
+	result, err := Compare(a, b)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if result.Divergence != nil || len(result.Tasks) != 0 || len(result.OnlyInA)+len(result.OnlyInB) != 0 {
+		t.Errorf("expected no differences, got %+v", result)
+	}
+}
+
+func TestCompare_TornLine(t *testing.T) {
+	dir := t.TempDir()
+	events := []schema.CanonicalEvent{
+		event(0, "exec-1", "executor", "running", schema.TypeMessage, "", 0),
+		event(1, "exec-1", "executor", "done", schema.TypeMessage, "", 0),
+	}
+	a := writeSession(t, dir, "a.jsonl", events...)
+	b := writeSession(t, dir, "b.jsonl", events...)
+
+	// A crash mid-write leaves a torn last line
+	f, err := os.OpenFile(b, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"ts":"2026-02-17T22:27:02Z","agent_id":"exec-1`)
+	_ = f.Close()
+
+	result, err := Compare(a, b)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if len(result.B.Skipped) != 1 || len(result.A.Skipped) != 0 {
+		t.Errorf("skipped = %v / %v, want the torn line of B only", result.A.Skipped, result.B.Skipped)
+	}
+	if result.B.Events != 2 || result.Divergence != nil {
+		t.Errorf("B = %+v, divergence = %+v, want the 2 valid events and no divergence", result.B, result.Divergence)
+	}
+}
+
+func TestCompare_DivergesAcrossChunks(t *testing.T) {
+	dir := t.TempDir()
+	var events []schema.CanonicalEvent
+	for i := 0; i < chunkSize+10; i++ {
+		events = append(events, event(i, "exec-1", "executor", "running", schema.TypeMessage, "", 0))
+	}
+	a := writeSession(t, dir, "a.jsonl", events...)
+	events[chunkSize+5].Type = schema.TypeError
+	b := writeSession(t, dir, "b.jsonl", events...)
+
+	result, err := Compare(a, b)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if d := result.Divergence; d == nil || d.Index != chunkSize+5 {
+		t.Errorf("divergence = %+v, want index %d", d, chunkSize+5)
+	}
+	if result.A.Events != chunkSize+10 {
+		t.Errorf("A events = %d, want %d", result.A.Events, chunkSize+10)
+	}
+}
+
+func TestDiverge_PrefixEnds(t *testing.T) {
+	a := []schema.CanonicalEvent{event(0, "a", "executor", "running", schema.TypeMessage, "", 0)}
+	b := append(a, event(1, "a", "executor", "done", schema.TypeMessage, "", 0))
+
+	d := divergeChunk(a, b)
+	if d == nil || d.Index != 1 || d.A != nil || d.B == nil {
+		t.Errorf("divergence = %+v, want index 1 with A ended", d)
+	}
+}
@@ -72,6 +72,8 @@ func (n *Normalizer) Normalize(raw schema.RawEvent) (*schema.CanonicalEvent, err
 	// Extract optional fields
 	parentAgentID := extractString(data, "parent_agent_id")
 	taskID := extractString(data, "task_id")
+	spanID := extractString(data, "span_id")
+	parentSpanID := extractString(data, "parent_span_id")
 	intentRef := extractString(data, "intent_ref")
 	rawRef := extractString(data, "raw_ref")
 
@@ -102,6 +104,8 @@ func (n *Normalizer) Normalize(raw schema.RawEvent) (*schema.CanonicalEvent, err
 		State:         state,
 		Type:          eventType,
 		TaskID:        taskID,
+		SpanID:        spanID,
+		ParentSpanID:  parentSpanID,
 		IntentRef:     intentRef,
 		Payload:       payload,
 		Metrics:       metrics,
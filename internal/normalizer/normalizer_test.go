@@ -305,3 +305,31 @@ func containsRedacted(data interface{}) bool {
 	}
 	return false
 }
+
+func TestNormalizer_Normalize_SpanFields(t *testing.T) {
+	n := New()
+
+	rawData := map[string]interface{}{
+		"ts":             "2024-01-01T12:00:00Z",
+		"run_id":         "run-123",
+		"provider":       "claude",
+		"agent_id":       "agent-456",
+		"role":           "executor",
+		"state":          "running",
+		"type":           "tool_call",
+		"span_id":        "span-1",
+		"parent_span_id": "span-0",
+	}
+
+	dataBytes, _ := json.Marshal(rawData)
+	event, err := n.Normalize(schema.RawEvent{Source: "test", Data: dataBytes, Received: time.Now()})
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	if event.SpanID != "span-1" {
+		t.Errorf("Expected span_id 'span-1', got '%s'", event.SpanID)
+	}
+	if event.ParentSpanID != "span-0" {
+		t.Errorf("Expected parent_span_id 'span-0', got '%s'", event.ParentSpanID)
+	}
+}
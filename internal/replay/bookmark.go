@@ -0,0 +1,131 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+)
+
+// bookmarkSuffix is appended to a replay file's path to name its
+// bookmark sidecar.
+const bookmarkSuffix = ".bookmarks.json"
+
+// Bookmark is a named replay position.
+type Bookmark struct {
+	Name     string    `json:"name"`
+	Position int       `json:"position"` // index into the loaded events
+	Ts       time.Time `json:"ts"`
+}
+
+// BookmarkPath returns the sidecar file bookmarks for path are saved in.
+func BookmarkPath(path string) string {
+	return path + bookmarkSuffix
+}
+
+// Bookmarks returns the bookmarks ordered by position.
+func (p *Player) Bookmarks() []Bookmark {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]Bookmark(nil), p.bookmarks...)
+}
+
+// AddBookmark bookmarks position under name, replacing any bookmark with
+// the same name, and saves the sidecar next to the loaded file. The
+// bookmarks are left unchanged if the sidecar cannot be saved.
+func (p *Player) AddBookmark(name string, position int) error {
+	if name == "" {
+		return errors.New("bookmark name is empty")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if position < 0 || position >= p.total() {
+		return fmt.Errorf("bookmark position %d out of range", position)
+	}
+
+	bm := Bookmark{Name: name, Position: position, Ts: p.tsAt(position)}
+	bookmarks := make([]Bookmark, 0, len(p.bookmarks)+1)
+	for _, other := range p.bookmarks {
+		if other.Name != name {
+			bookmarks = append(bookmarks, other)
+		}
+	}
+	bookmarks = append(bookmarks, bm)
+	sort.SliceStable(bookmarks, func(i, j int) bool {
+		return bookmarks[i].Position < bookmarks[j].Position
+	})
+	if err := p.saveBookmarks(bookmarks); err != nil {
+		return err
+	}
+	p.bookmarks = bookmarks
+	return nil
+}
+
+// NextBookmark returns the first bookmark after position, wrapping
+// around to the first one.
+func (p *Player) NextBookmark(position int) (Bookmark, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.bookmarks) == 0 {
+		return Bookmark{}, false
+	}
+	for _, bm := range p.bookmarks {
+		if bm.Position > position {
+			return bm, true
+		}
+	}
+	return p.bookmarks[0], true
+}
+
+// saveBookmarks writes bookmarks to the sidecar. Nothing is written when
+// no file is loaded. The caller must hold p.mu.
+func (p *Player) saveBookmarks(bookmarks []Bookmark) error {
+	if p.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(bookmarks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode bookmarks: %w", err)
+	}
+	if err := os.WriteFile(BookmarkPath(p.path), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write bookmarks: %w", err)
+	}
+	return nil
+}
+
+// loadBookmarks reads the sidecar for the loaded file, if there is one.
+// Unreadable sidecars and out-of-range positions are skipped with a
+// warning rather than failing the load. The caller must hold p.mu.
+func (p *Player) loadBookmarks() {
+	p.bookmarks = nil
+	data, err := os.ReadFile(BookmarkPath(p.path))
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		log.Printf("[WARN] read bookmarks: %v", err)
+		return
+	}
+
+	var bookmarks []Bookmark
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		log.Printf("[WARN] decode bookmarks %s: %v", BookmarkPath(p.path), err)
+		return
+	}
+	for _, bm := range bookmarks {
+		if bm.Position < 0 || bm.Position >= p.total() {
+			log.Printf("[WARN] bookmark %q: position %d out of range", bm.Name, bm.Position)
+			continue
+		}
+		p.bookmarks = append(p.bookmarks, bm)
+	}
+	sort.SliceStable(p.bookmarks, func(i, j int) bool {
+		return p.bookmarks[i].Position < p.bookmarks[j].Position
+	})
+}
@@ -0,0 +1,98 @@
+package replay
+
+import (
+	"os"
+	"testing"
+)
+
+func TestBookmarks_SavedToSidecar(t *testing.T) {
+	path := createTestJSONL(t, t.TempDir(), notableEvents())
+
+	player := NewPlayer()
+	if err := player.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := player.AddBookmark("failure", 3); err != nil {
+		t.Fatalf("AddBookmark failed: %v", err)
+	}
+	if err := player.AddBookmark("start", 0); err != nil {
+		t.Fatalf("AddBookmark failed: %v", err)
+	}
+	if err := player.AddBookmark("failure", 2); err != nil {
+		t.Fatalf("AddBookmark failed: %v", err)
+	}
+	if err := player.AddBookmark("", 1); err == nil {
+		t.Error("expected an error for an empty name")
+	}
+	if err := player.AddBookmark("late", 99); err == nil {
+		t.Error("expected an error for an out-of-range position")
+	}
+
+	if _, err := os.Stat(BookmarkPath(path)); err != nil {
+		t.Fatalf("expected sidecar file: %v", err)
+	}
+
+	reloaded := NewPlayer()
+	if err := reloaded.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	got := reloaded.Bookmarks()
+	if len(got) != 2 || got[0].Name != "start" || got[1].Name != "failure" || got[1].Position != 2 {
+		t.Errorf("unexpected reloaded bookmarks: %+v", got)
+	}
+}
+
+func TestAddBookmark_SaveFails(t *testing.T) {
+	path := createTestJSONL(t, t.TempDir(), notableEvents())
+
+	player := NewPlayer()
+	if err := player.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	// A directory in the sidecar's place makes the save fail
+	if err := os.Mkdir(BookmarkPath(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.AddBookmark("failure", 3); err == nil {
+		t.Fatal("expected an error when the sidecar cannot be written")
+	}
+	if got := player.Bookmarks(); len(got) != 0 {
+		t.Errorf("bookmark kept after a failed save: %+v", got)
+	}
+}
+
+func TestNextBookmark_Wraps(t *testing.T) {
+	player := NewPlayer()
+	if err := player.LoadFile(createTestJSONL(t, t.TempDir(), notableEvents())); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if _, ok := player.NextBookmark(0); ok {
+		t.Error("expected no bookmark before any are added")
+	}
+
+	_ = player.AddBookmark("one", 1)
+	_ = player.AddBookmark("five", 5)
+
+	if bm, _ := player.NextBookmark(1); bm.Name != "five" {
+		t.Errorf("NextBookmark(1) = %s, want five", bm.Name)
+	}
+	if bm, _ := player.NextBookmark(5); bm.Name != "one" {
+		t.Errorf("NextBookmark(5) = %s, want one (wrapped)", bm.Name)
+	}
+}
+
+func TestLoadBookmarks_SkipsInvalid(t *testing.T) {
+	path := createTestJSONL(t, t.TempDir(), notableEvents())
+	sidecar := `[{"name":"ok","position":1},{"name":"gone","position":42}]`
+	if err := os.WriteFile(BookmarkPath(path), []byte(sidecar), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	player := NewPlayer()
+	if err := player.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if got := player.Bookmarks(); len(got) != 1 || got[0].Name != "ok" {
+		t.Errorf("expected only the in-range bookmark, got %+v", got)
+	}
+}
@@ -0,0 +1,184 @@
+package replay
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chamdom/omc-agent-tui/internal/codec"
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// ExpandPaths resolves replay sources to event files. Each argument may
+// be a file, a directory (every .jsonl and .jsonl.gz file directly inside
+// it) or a glob pattern. Files are returned in order without duplicates.
+func ExpandPaths(args []string) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("glob %s: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", arg)
+			}
+			for _, match := range matches {
+				add(match)
+			}
+			continue
+		}
+
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", arg, err)
+		}
+		if !info.IsDir() {
+			add(arg)
+			continue
+		}
+
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, fmt.Errorf("read dir %s: %w", arg, err)
+		}
+		var files []string
+		for _, entry := range entries {
+			if !entry.IsDir() && codec.IsEventFile(entry.Name()) {
+				files = append(files, filepath.Join(arg, entry.Name()))
+			}
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no event files in %s", arg)
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			add(file)
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, errors.New("no replay files given")
+	}
+	return paths, nil
+}
+
+// LoadFiles loads several JSONL files and merges their events into one
+// timeline ordered by timestamp. Events without a RawRef get the path of
+// the file they came from. A single path behaves exactly like LoadFile;
+// merged files are held in memory, so each must be under 100MB.
+// Bookmarks are only saved to a sidecar for a single file.
+func (p *Player) LoadFiles(paths []string) error {
+	if len(paths) == 0 {
+		return errors.New("no replay files given")
+	}
+	if len(paths) == 1 {
+		return p.LoadFile(paths[0])
+	}
+
+	tolerant := p.isTolerant()
+	report := LoadReport{Files: len(paths)}
+	sources := make([][]schema.CanonicalEvent, len(paths))
+	for i, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("stat file: %w", err)
+		}
+		if info.Size() > maxFileSize {
+			return fmt.Errorf("%s: file size %d exceeds max %d for merged replay", path, info.Size(), maxFileSize)
+		}
+
+		events, skipped, err := readFile(path, tolerant)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		report.Events += len(events)
+		report.Skipped = append(report.Skipped, skipped...)
+		for j := range events {
+			if events[j].RawRef == "" {
+				events[j].RawRef = path
+			}
+		}
+		sources[i] = events
+	}
+
+	merged := mergeEvents(sources)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setEvents(merged, "")
+	p.report = report
+	return nil
+}
+
+// mergeEvents k-way merges event lists that are each sorted by timestamp.
+// Events with equal timestamps keep the order of their sources.
+func mergeEvents(sources [][]schema.CanonicalEvent) []schema.CanonicalEvent {
+	total := 0
+	h := make(mergeHeap, 0, len(sources))
+	for i, events := range sources {
+		total += len(events)
+		if len(events) > 0 {
+			h = append(h, mergeCursor{events: events, source: i})
+		}
+	}
+	heap.Init(&h)
+
+	merged := make([]schema.CanonicalEvent, 0, total)
+	for h.Len() > 0 {
+		top := &h[0]
+		merged = append(merged, top.events[top.pos])
+		top.pos++
+		if top.pos == len(top.events) {
+			heap.Pop(&h)
+		} else {
+			heap.Fix(&h, 0)
+		}
+	}
+	return merged
+}
+
+// mergeCursor is the read position in one source of a merge.
+type mergeCursor struct {
+	events []schema.CanonicalEvent
+	pos    int
+	source int
+}
+
+// mergeHeap orders cursors by their next event's timestamp, then by
+// source order.
+type mergeHeap []mergeCursor
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	a, b := h[i].events[h[i].pos].Ts, h[j].events[h[j].pos].Ts
+	if a.Equal(b) {
+		return h[i].source < h[j].source
+	}
+	return a.Before(b)
+}
+
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *mergeHeap) Push(x any) { *h = append(*h, x.(mergeCursor)) }
+
+func (h *mergeHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
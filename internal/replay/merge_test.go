@@ -0,0 +1,83 @@
+package replay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// sessionFile writes events for agent at the given second offsets to
+// dir/name.
+func sessionFile(t *testing.T, dir, name, agent string, seconds ...int) string {
+	t.Helper()
+	base := time.Date(2026, 2, 17, 22, 27, 0, 0, time.UTC)
+	var events []schema.CanonicalEvent
+	for _, sec := range seconds {
+		events = append(events, schema.CanonicalEvent{
+			Ts: base.Add(time.Duration(sec) * time.Second), RunID: "run-1", Provider: "claude",
+			AgentID: agent, Role: "executor", State: "running", Type: "message",
+		})
+	}
+	path := createTestJSONL(t, dir, events)
+	target := filepath.Join(dir, name)
+	if err := os.Rename(path, target); err != nil {
+		t.Fatal(err)
+	}
+	return target
+}
+
+func TestLoadFiles_MergesByTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	a := sessionFile(t, dir, "a.jsonl", "a1", 0, 2, 4)
+	b := sessionFile(t, dir, "b.jsonl", "b1", 1, 2, 5)
+
+	player := NewPlayer()
+	if err := player.LoadFiles([]string{a, b}); err != nil {
+		t.Fatalf("LoadFiles failed: %v", err)
+	}
+
+	events := player.Events(0, player.Total())
+	want := []struct{ agent, ref string }{
+		{"a1", a}, {"b1", b}, {"a1", a}, {"b1", b}, {"a1", a}, {"b1", b},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(events))
+	}
+	for i, w := range want {
+		if events[i].AgentID != w.agent || events[i].RawRef != w.ref {
+			t.Errorf("event %d = %s from %s, want %s from %s", i, events[i].AgentID, events[i].RawRef, w.agent, w.ref)
+		}
+	}
+}
+
+func TestExpandPaths(t *testing.T) {
+	dir := t.TempDir()
+	a := sessionFile(t, dir, "a.jsonl", "a1", 0)
+	b := sessionFile(t, dir, "b.jsonl.gz", "b1", 1)
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := ExpandPaths([]string{dir})
+	if err != nil {
+		t.Fatalf("ExpandPaths(dir) failed: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != a || paths[1] != b {
+		t.Errorf("expected [%s %s], got %v", a, b, paths)
+	}
+
+	paths, err = ExpandPaths([]string{filepath.Join(dir, "*.jsonl"), a})
+	if err != nil {
+		t.Fatalf("ExpandPaths(glob) failed: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != a {
+		t.Errorf("expected glob to match only %s once, got %v", a, paths)
+	}
+
+	if _, err := ExpandPaths([]string{filepath.Join(dir, "*.none")}); err == nil {
+		t.Error("expected an error for a glob without matches")
+	}
+}
@@ -0,0 +1,155 @@
+package replay
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// NotableKind classifies an event worth jumping to while investigating a
+// run.
+type NotableKind string
+
+const (
+	NotableError             NotableKind = "error"              // error event
+	NotableReplan            NotableKind = "replan"             // plan was revised
+	NotableVerifyFail        NotableKind = "verify_fail"        // verify with result "fail"
+	NotableRecover           NotableKind = "recover"            // recovery after a failure
+	NotableInvalidTransition NotableKind = "invalid_transition" // state change the schema forbids
+)
+
+// notableChunk is how many events buildNotable reads per hold of p.mu.
+const notableChunk = 1024
+
+// Notable is an indexed notable event.
+type Notable struct {
+	Position int // index into the loaded events
+	Kind     NotableKind
+	AgentID  string
+	Ts       time.Time
+}
+
+// Notable returns every notable event in playback order. The index is
+// built on first use; for streamed files that is one sequential pass
+// over the file.
+func (p *Player) Notable() []Notable {
+	return append([]Notable(nil), p.notableIndex()...)
+}
+
+// NextNotable returns the first notable event after position.
+func (p *Player) NextNotable(position int) (Notable, bool) {
+	for _, n := range p.notableIndex() {
+		if n.Position > position {
+			return n, true
+		}
+	}
+	return Notable{}, false
+}
+
+// PrevNotable returns the last notable event before position.
+func (p *Player) PrevNotable(position int) (Notable, bool) {
+	notable := p.notableIndex()
+	for i := len(notable) - 1; i >= 0; i-- {
+		if notable[i].Position < position {
+			return notable[i], true
+		}
+	}
+	return Notable{}, false
+}
+
+// notableIndex returns the notable index, building it on first use. The
+// index is only read by callers and must not be modified.
+func (p *Player) notableIndex() []Notable {
+	p.notableMu.Lock()
+	defer p.notableMu.Unlock()
+
+	p.mu.RLock()
+	notable, built, loads := p.notable, p.notableBuilt, p.loads
+	p.mu.RUnlock()
+	if built {
+		return notable
+	}
+
+	notable, ok := p.buildNotable(loads)
+	if !ok {
+		return nil
+	}
+	p.mu.Lock()
+	if p.loads == loads {
+		p.notable = notable
+		p.notableBuilt = true
+	}
+	p.mu.Unlock()
+	return notable
+}
+
+// buildNotable indexes the notable events of the load numbered loads. The
+// events are read notableChunk at a time, holding p.mu only for reading
+// and only per chunk, so playback is not blocked for the whole pass over
+// a streamed file. It reports false if another file was loaded meanwhile.
+func (p *Player) buildNotable(loads int) ([]Notable, bool) {
+	var notable []Notable
+
+	// Last state per agent, keyed by run so reused agent IDs in a later
+	// run do not look like invalid transitions
+	states := make(map[[2]string]schema.AgentState)
+
+	chunk := make([]*schema.CanonicalEvent, 0, notableChunk)
+	for from := 0; ; from += notableChunk {
+		p.mu.RLock()
+		if p.loads != loads {
+			p.mu.RUnlock()
+			return nil, false
+		}
+		chunk = chunk[:0]
+		for i := from; i < p.total() && i < from+notableChunk; i++ {
+			chunk = append(chunk, p.eventAt(i))
+		}
+		p.mu.RUnlock()
+		if len(chunk) == 0 {
+			return notable, true
+		}
+
+		for j, evt := range chunk {
+			if evt == nil {
+				continue
+			}
+
+			key := [2]string{evt.RunID, evt.AgentID}
+			prev, seen := states[key]
+			states[key] = evt.State
+
+			kind := classify(*evt)
+			if kind == "" && seen && prev != evt.State && !schema.IsValidTransition(prev, evt.State) {
+				kind = NotableInvalidTransition
+			}
+			if kind != "" {
+				notable = append(notable, Notable{
+					Position: from + j,
+					Kind:     kind,
+					AgentID:  evt.AgentID,
+					Ts:       evt.Ts,
+				})
+			}
+		}
+	}
+}
+
+// classify returns the notable kind implied by an event's type, or "".
+func classify(event schema.CanonicalEvent) NotableKind {
+	switch event.Type {
+	case schema.TypeError:
+		return NotableError
+	case schema.TypeReplan:
+		return NotableReplan
+	case schema.TypeRecover:
+		return NotableRecover
+	case schema.TypeVerify:
+		var payload schema.VerifyPayload
+		if len(event.Payload) > 0 && json.Unmarshal(event.Payload, &payload) == nil && payload.Result == "fail" {
+			return NotableVerifyFail
+		}
+	}
+	return ""
+}
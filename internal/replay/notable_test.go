@@ -0,0 +1,126 @@
+package replay
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+func notableEvents() []schema.CanonicalEvent {
+	base := time.Date(2026, 2, 17, 22, 27, 0, 0, time.UTC)
+	event := func(i int, agent string, state schema.AgentState, typ schema.EventType, payload any) schema.CanonicalEvent {
+		evt := schema.CanonicalEvent{
+			Ts: base.Add(time.Duration(i) * time.Second), RunID: "run-1", Provider: "claude",
+			AgentID: agent, Role: "executor", State: state, Type: typ,
+		}
+		if payload != nil {
+			evt.Payload, _ = json.Marshal(payload)
+		}
+		return evt
+	}
+	return []schema.CanonicalEvent{
+		event(0, "a1", "running", "message", nil),
+		event(1, "a1", "running", "verify", schema.VerifyPayload{Result: "pass"}),
+		event(2, "a1", "running", "verify", schema.VerifyPayload{Result: "fail"}),
+		event(3, "a1", "error", "error", nil),
+		event(4, "a1", "running", "recover", nil),
+		event(5, "a2", "done", "message", nil),
+		event(6, "a2", "running", "message", nil), // done -> running is invalid
+		event(7, "a1", "running", "replan", nil),
+	}
+}
+
+func TestNotable_Index(t *testing.T) {
+	player := NewPlayer()
+	if err := player.LoadFile(createTestJSONL(t, t.TempDir(), notableEvents())); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	want := []struct {
+		pos  int
+		kind NotableKind
+	}{
+		{2, NotableVerifyFail},
+		{3, NotableError},
+		{4, NotableRecover},
+		{6, NotableInvalidTransition},
+		{7, NotableReplan},
+	}
+	got := player.Notable()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d notable events, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Position != w.pos || got[i].Kind != w.kind {
+			t.Errorf("notable[%d] = %d %s, want %d %s", i, got[i].Position, got[i].Kind, w.pos, w.kind)
+		}
+	}
+}
+
+func TestNotable_NextPrev(t *testing.T) {
+	player := NewPlayer()
+	if err := player.LoadFile(createTestJSONL(t, t.TempDir(), notableEvents())); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	if n, ok := player.NextNotable(3); !ok || n.Position != 4 {
+		t.Errorf("NextNotable(3) = %d %v, want 4", n.Position, ok)
+	}
+	if n, ok := player.PrevNotable(3); !ok || n.Position != 2 {
+		t.Errorf("PrevNotable(3) = %d %v, want 2", n.Position, ok)
+	}
+	if _, ok := player.NextNotable(7); ok {
+		t.Error("expected no notable event after the last one")
+	}
+	if _, ok := player.PrevNotable(2); ok {
+		t.Error("expected no notable event before the first one")
+	}
+}
+
+func TestNotable_Streaming(t *testing.T) {
+	player := NewPlayer()
+	if err := player.LoadStream(createTestJSONL(t, t.TempDir(), notableEvents())); err != nil {
+		t.Fatalf("LoadStream failed: %v", err)
+	}
+	defer func() { _ = player.Close() }()
+
+	if got := len(player.Notable()); got != 5 {
+		t.Errorf("expected 5 notable events from a stream, got %d", got)
+	}
+}
+
+func TestNotable_SpansChunks(t *testing.T) {
+	base := time.Date(2026, 2, 17, 22, 27, 0, 0, time.UTC)
+	events := make([]schema.CanonicalEvent, notableChunk*2+10)
+	for i := range events {
+		events[i] = schema.CanonicalEvent{
+			Ts: base.Add(time.Duration(i) * time.Second), RunID: "run-1", Provider: "claude",
+			AgentID: "a1", Role: "executor", State: "running", Type: "message",
+		}
+	}
+	events[notableChunk+3].Type = schema.TypeError
+	events[notableChunk*2+5].Type = schema.TypeReplan
+
+	player := NewPlayer()
+	if err := player.LoadFile(createTestJSONL(t, t.TempDir(), events)); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	// Playback may step while the index is built
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			player.StepForward()
+		}
+	}()
+	if n, ok := player.NextNotable(0); !ok || n.Position != notableChunk+3 || n.Kind != NotableError {
+		t.Errorf("NextNotable(0) = %+v %v, want error at %d", n, ok, notableChunk+3)
+	}
+	<-done
+	if n, ok := player.PrevNotable(len(events)); !ok || n.Position != notableChunk*2+5 {
+		t.Errorf("PrevNotable(end) = %+v %v, want replan at %d", n, ok, notableChunk*2+5)
+	}
+}
@@ -0,0 +1,104 @@
+package replay
+
+import (
+	"sort"
+	"time"
+)
+
+// SetRange limits playback to events [from, to); to < 0 plays to the
+// last event. Play starts at from when the cursor is outside the range,
+// and Advance stops (or loops) at to; seeking with SetCursor is not
+// restricted.
+func (p *Player) SetRange(from, to int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setRange(from, to)
+}
+
+// SetTimeRange limits playback to events with start <= Ts < end.
+// A zero start or end leaves that side open.
+func (p *Player) SetTimeRange(start, end time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	from, to := 0, -1
+	if !start.IsZero() {
+		from = p.indexOf(start)
+	}
+	if !end.IsZero() {
+		to = p.indexOf(end)
+	}
+	p.setRange(from, to)
+}
+
+// Range returns the playback range [from, to).
+func (p *Player) Range() (from, to int) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.rangeFrom, p.end()
+}
+
+// IndexOf returns the index of the first event at or after ts, or Total
+// if there is none.
+func (p *Player) IndexOf(ts time.Time) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.indexOf(ts)
+}
+
+// SetLoop makes playback start over from the range start instead of
+// pausing at the end.
+func (p *Player) SetLoop(loop bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loop = loop
+}
+
+// SetIdleCompression shortens idle stretches: when two consecutive events
+// are more than gap apart, Advance waits only to of virtual time for the
+// second. For example gap 5s and to 300ms plays any pause over 5s in
+// 300ms. A zero gap disables compression.
+func (p *Player) SetIdleCompression(gap, to time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if to < 0 {
+		to = 0
+	}
+	p.idleGap = gap
+	p.idleTo = to
+}
+
+// setRange implements SetRange. The caller must hold p.mu.
+func (p *Player) setRange(from, to int) {
+	total := p.total()
+	if to > total {
+		to = total
+	}
+	if from < 0 {
+		from = 0
+	}
+	if from > total {
+		from = total
+	}
+	if to >= 0 && from > to {
+		from = to
+	}
+	p.rangeFrom = from
+	p.rangeTo = to
+}
+
+// end returns the exclusive end of the playback range.
+// The caller must hold p.mu.
+func (p *Player) end() int {
+	if p.rangeTo >= 0 && p.rangeTo < p.total() {
+		return p.rangeTo
+	}
+	return p.total()
+}
+
+// indexOf implements IndexOf. The caller must hold p.mu.
+func (p *Player) indexOf(ts time.Time) int {
+	return sort.Search(p.total(), func(i int) bool {
+		return !p.tsAt(i).Before(ts)
+	})
+}
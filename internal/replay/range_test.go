@@ -0,0 +1,104 @@
+package replay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// rangePlayer loads events at the given second offsets.
+func rangePlayer(t *testing.T, seconds ...int) (*Player, time.Time) {
+	t.Helper()
+	base := time.Date(2026, 2, 17, 22, 27, 0, 0, time.UTC)
+	var events []schema.CanonicalEvent
+	for _, sec := range seconds {
+		events = append(events, schema.CanonicalEvent{
+			Ts: base.Add(time.Duration(sec) * time.Second), RunID: "run-1", Provider: "claude",
+			AgentID: "a1", Role: "executor", State: "running", Type: "message",
+		})
+	}
+	player := NewPlayer()
+	if err := player.LoadFile(createTestJSONL(t, t.TempDir(), events)); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	return player, base
+}
+
+func TestSetRange_PlaysSubRange(t *testing.T) {
+	player, _ := rangePlayer(t, 0, 1, 2, 3, 4)
+	player.SetRange(1, 3)
+
+	player.Play()
+	if player.Cursor() != 1 {
+		t.Fatalf("expected Play to start at the range start, got cursor %d", player.Cursor())
+	}
+	now := time.Now()
+	player.Advance(now)
+	got := player.Advance(now.Add(time.Minute))
+	if player.Cursor() != 3 {
+		t.Errorf("expected playback to stop at 3, got cursor %d", player.Cursor())
+	}
+	if len(got) != 2 {
+		t.Errorf("expected 2 events delivered, got %d", len(got))
+	}
+	if player.IsPlaying() {
+		t.Error("expected playback to pause at the range end")
+	}
+}
+
+func TestSetTimeRange(t *testing.T) {
+	player, base := rangePlayer(t, 0, 1, 2, 3, 4)
+
+	player.SetTimeRange(base.Add(1500*time.Millisecond), base.Add(3*time.Second))
+	if from, to := player.Range(); from != 2 || to != 3 {
+		t.Errorf("expected range [2, 3), got [%d, %d)", from, to)
+	}
+
+	player.SetTimeRange(time.Time{}, time.Time{})
+	if from, to := player.Range(); from != 0 || to != 5 {
+		t.Errorf("expected open range [0, 5), got [%d, %d)", from, to)
+	}
+}
+
+func TestSetLoop(t *testing.T) {
+	player, _ := rangePlayer(t, 0, 1, 2)
+	player.SetRange(1, -1)
+	player.SetLoop(true)
+
+	player.Play()
+	now := time.Now()
+	player.Advance(now)
+	got := player.Advance(now.Add(time.Minute))
+	if len(got) != 2 {
+		t.Errorf("expected the rest of the range delivered, got %d", len(got))
+	}
+	if !player.IsPlaying() || player.Cursor() != 1 {
+		t.Errorf("expected looping back to 1 while playing, got cursor %d playing %v", player.Cursor(), player.IsPlaying())
+	}
+}
+
+func TestSetIdleCompression(t *testing.T) {
+	player, _ := rangePlayer(t, 0, 3, 60)
+	player.SetIdleCompression(5*time.Second, 300*time.Millisecond)
+
+	player.Play()
+	now := time.Now()
+	if got := player.Advance(now); len(got) != 1 {
+		t.Fatalf("expected first event immediately, got %d", len(got))
+	}
+	// The 3s gap is under the threshold and plays in full
+	if got := player.Advance(now.Add(2 * time.Second)); len(got) != 0 {
+		t.Errorf("expected to wait inside the short gap, got %d", len(got))
+	}
+	if got := player.Advance(now.Add(3 * time.Second)); len(got) != 1 {
+		t.Fatalf("expected second event after 3s, got %d", len(got))
+	}
+	// The 57s gap shrinks to 300ms
+	if got := player.Advance(now.Add(3200 * time.Millisecond)); len(got) != 0 {
+		t.Errorf("expected to wait inside the compressed gap, got %d", len(got))
+	}
+	if got := player.Advance(now.Add(3300 * time.Millisecond)); len(got) != 1 {
+		t.Errorf("expected last event after 300ms, got %d", len(got))
+	}
+}
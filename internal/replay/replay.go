@@ -3,27 +3,57 @@ package replay
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/chamdom/omc-agent-tui/internal/codec"
 	"github.com/chamdom/omc-agent-tui/pkg/schema"
 )
 
-const maxFileSize = 100 * 1024 * 1024 // 100MB
+// maxFileSize is the largest file LoadFile reads into memory (100MB).
+var maxFileSize int64 = 100 * 1024 * 1024
 
 // Player manages JSONL event replay with virtual clock and step navigation.
+// Events are held in memory, or streamed from disk for files over 100MB.
 type Player struct {
 	events    []schema.CanonicalEvent
+	stream    *stream // set instead of events in streaming mode
 	position  int
 	speed     float64
 	playing   bool
 	startTime time.Time       // real-world time when playback started
 	pauseTime time.Time       // real-world time when paused
 	baseTime  time.Time       // virtual time at position 0
-	mu        sync.RWMutex
+
+	// incremental clock driven by Advance
+	delivered int           // events handed out by Advance so far
+	vnow      time.Time     // virtual time reached by Advance
+	lastTick  time.Time     // real time of the previous Advance
+	idleGap   time.Duration // gaps longer than this are compressed; 0 = never
+	idleTo    time.Duration // virtual wait a compressed gap is shortened to
+
+	// playback range [rangeFrom, rangeTo); rangeTo -1 means the last event
+	rangeFrom int
+	rangeTo   int
+	loop      bool
+
+	path         string     // loaded file; bookmarks are saved next to it
+	bookmarks    []Bookmark // ordered by position
+	notable      []Notable  // built on first use, then never modified
+	notableBuilt bool
+	notableMu    sync.Mutex // serializes index builds; taken before mu
+	loads        int        // bumped by every load, to spot a stale index build
+
+	tolerant bool       // skip invalid lines instead of failing the load
+	report   LoadReport // outcome of the latest load
+
+	mu sync.RWMutex
 }
 
 // NewPlayer creates a new replay player.
@@ -33,63 +63,108 @@ func NewPlayer() *Player {
 		position: 0,
 		speed:    1.0,
 		playing:  false,
+		rangeTo:  -1,
 	}
 }
 
 // LoadFile loads events from a JSONL file and sorts them by timestamp.
-// Returns error if file > 100MB (requires streaming mode).
+// Files ending in ".gz" are decompressed on the fly.
+// Uncompressed files over 100MB are streamed instead (see LoadStream);
+// compressed files over 100MB are rejected.
 func (p *Player) LoadFile(path string) error {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	// Check file size
 	info, err := os.Stat(path)
 	if err != nil {
 		return fmt.Errorf("stat file: %w", err)
 	}
 	if info.Size() > maxFileSize {
-		return fmt.Errorf("file size %d exceeds max %d (streaming mode required)", info.Size(), maxFileSize)
+		if codec.IsCompressed(path) {
+			return fmt.Errorf("file size %d exceeds max %d (compressed files cannot be streamed)", info.Size(), maxFileSize)
+		}
+		return p.LoadStream(path)
 	}
 
-	// Open and parse JSONL
-	f, err := os.Open(path)
+	events, skipped, err := readFile(path, p.isTolerant())
 	if err != nil {
-		return fmt.Errorf("open file: %w", err)
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setEvents(events, path)
+	p.report = LoadReport{Files: 1, Events: len(events), Skipped: skipped}
+	return nil
+}
+
+// readFile parses and validates a JSONL file and returns its events
+// sorted by timestamp. In tolerant mode invalid lines are returned as
+// skipped instead of failing.
+func readFile(path string, tolerant bool) ([]schema.CanonicalEvent, []LineError, error) {
+	// Open and parse JSONL (decompressed transparently by extension)
+	f, err := codec.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open file: %w", err)
 	}
 	defer func() { _ = f.Close() }()
 
 	var events []schema.CanonicalEvent
-	scanner := bufio.NewScanner(f)
+	sk := &skipper{path: path, tolerant: tolerant}
+	// Lines are read without a size limit so one large tool result
+	// cannot abort the load
+	reader := bufio.NewReaderSize(f, 64*1024)
 	lineNum := 0
 
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue // skip empty lines
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			// A crashed writer leaves a compressed file cut off mid-stream
+			if !codec.IsTruncated(readErr) || sk.skip(lineNum+1, "truncated", readErr) != nil {
+				return nil, nil, fmt.Errorf("scan file: %w", readErr)
+			}
+			break
 		}
-
-		var evt schema.CanonicalEvent
-		if err := json.Unmarshal(line, &evt); err != nil {
-			return fmt.Errorf("line %d: invalid JSON: %w", lineNum, err)
+		if len(line) > 0 {
+			lineNum++
+			if trimmed := trimNewline(line); len(trimmed) > 0 {
+				evt, reason, err := decodeLine(trimmed)
+				if err != nil {
+					if err := sk.skip(lineNum, reason, err); err != nil {
+						return nil, nil, err
+					}
+				} else {
+					events = append(events, evt)
+				}
+			}
 		}
-
-		if err := evt.Validate(); err != nil {
-			return fmt.Errorf("line %d: invalid event: %w", lineNum, err)
+		if readErr != nil {
+			break
 		}
-
-		events = append(events, evt)
-	}
-
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scan file: %w", err)
 	}
 
 	// Sort by timestamp
-	sort.Slice(events, func(i, j int) bool {
+	sort.SliceStable(events, func(i, j int) bool {
 		return events[i].Ts.Before(events[j].Ts)
 	})
+	return events, sk.skipped, nil
+}
 
+// decodeLine parses and validates one JSONL line. On failure it also
+// returns what was wrong with the line.
+func decodeLine(line []byte) (schema.CanonicalEvent, string, error) {
+	var evt schema.CanonicalEvent
+	if err := json.Unmarshal(line, &evt); err != nil {
+		return evt, "invalid JSON", err
+	}
+	if err := evt.Validate(); err != nil {
+		return evt, "invalid event", err
+	}
+	return evt, "", nil
+}
+
+// setEvents replaces the loaded events with events, already sorted by
+// timestamp, and rewinds. path names their file for bookmarks; "" keeps
+// bookmarks in memory only. The caller must hold p.mu.
+func (p *Player) setEvents(events []schema.CanonicalEvent, path string) {
+	p.closeStream()
 	p.events = events
 	p.position = 0
 	p.playing = false
@@ -98,10 +173,107 @@ func (p *Player) LoadFile(path string) error {
 	if len(p.events) > 0 {
 		p.baseTime = p.events[0].Ts
 	}
+	p.setCursor(0)
+	p.loaded(path)
+}
+
+// LoadStream indexes an uncompressed JSONL file in one pass and then
+// reads events from disk on demand, keeping memory bounded regardless of
+// file size. Seek, stepping and speed changes work as with LoadFile.
+// Call Close to release the file.
+func (p *Player) LoadStream(path string) error {
+	st, err := openStream(path, p.isTolerant())
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
+	p.closeStream()
+	p.events = nil
+	p.stream = st
+	p.position = 0
+	p.playing = false
+	if st.Len() > 0 {
+		p.baseTime = st.Ts(0)
+	}
+	p.setCursor(0)
+	p.loaded(path)
+	p.report = LoadReport{Files: 1, Events: st.Len(), Skipped: st.skipped}
 	return nil
 }
 
+// loaded resets per-file state after path was loaded and reads its
+// bookmarks. The caller must hold p.mu.
+func (p *Player) loaded(path string) {
+	p.path = path
+	p.loads++
+	p.rangeFrom = 0
+	p.rangeTo = -1
+	p.notable = nil
+	p.notableBuilt = false
+	p.bookmarks = nil
+	if path != "" {
+		p.loadBookmarks()
+	}
+}
+
+// Streaming reports whether events are read from disk on demand.
+func (p *Player) Streaming() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.stream != nil
+}
+
+// Close releases the file held open in streaming mode.
+func (p *Player) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closeStream()
+}
+
+// closeStream closes and clears the stream, if any.
+// The caller must hold p.mu.
+func (p *Player) closeStream() error {
+	if p.stream == nil {
+		return nil
+	}
+	err := p.stream.Close()
+	p.stream = nil
+	return err
+}
+
+// total returns the number of loaded events. The caller must hold p.mu.
+func (p *Player) total() int {
+	if p.stream != nil {
+		return p.stream.Len()
+	}
+	return len(p.events)
+}
+
+// tsAt returns the timestamp of event i. The caller must hold p.mu.
+func (p *Player) tsAt(i int) time.Time {
+	if p.stream != nil {
+		return p.stream.Ts(i)
+	}
+	return p.events[i].Ts
+}
+
+// eventAt returns event i, or nil if it cannot be read.
+// The caller must hold p.mu.
+func (p *Player) eventAt(i int) *schema.CanonicalEvent {
+	if p.stream == nil {
+		return &p.events[i]
+	}
+	evt, err := p.stream.Event(i)
+	if err != nil {
+		log.Printf("[WARN] replay stream: %v", err)
+		return nil
+	}
+	return &evt
+}
+
 // Play starts playback from current position.
 func (p *Player) Play() {
 	p.mu.Lock()
@@ -111,12 +283,18 @@ func (p *Player) Play() {
 		return
 	}
 
+	// Outside the range, playback starts at the range start
+	if p.delivered < p.rangeFrom || p.delivered >= p.end() {
+		p.setCursor(p.rangeFrom)
+	}
+
 	p.playing = true
 	p.startTime = time.Now()
+	p.lastTick = time.Time{}
 
 	// If resuming from pause, adjust startTime to account for elapsed virtual time
-	if p.position > 0 && len(p.events) > 0 {
-		virtualElapsed := p.events[p.position].Ts.Sub(p.baseTime)
+	if p.position > 0 && p.total() > 0 {
+		virtualElapsed := p.tsAt(p.position).Sub(p.baseTime)
 		scaledElapsed := time.Duration(float64(virtualElapsed) / p.speed)
 		p.startTime = time.Now().Add(-scaledElapsed)
 	}
@@ -141,9 +319,9 @@ func (p *Player) Stop() {
 	defer p.mu.Unlock()
 
 	p.playing = false
-	p.position = 0
 	p.startTime = time.Time{}
 	p.pauseTime = time.Time{}
+	p.setCursor(0)
 }
 
 // SetSpeed sets playback speed (1.0/4.0/8.0/16.0).
@@ -156,8 +334,8 @@ func (p *Player) SetSpeed(speed float64) {
 	}
 
 	// If playing, adjust startTime to maintain virtual position
-	if p.playing && p.position > 0 && len(p.events) > 0 {
-		virtualElapsed := p.events[p.position].Ts.Sub(p.baseTime)
+	if p.playing && p.position > 0 && p.total() > 0 {
+		virtualElapsed := p.tsAt(p.position).Sub(p.baseTime)
 		oldScaledElapsed := time.Duration(float64(virtualElapsed) / p.speed)
 		newScaledElapsed := time.Duration(float64(virtualElapsed) / speed)
 
@@ -173,7 +351,7 @@ func (p *Player) StepForward() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if p.position < len(p.events)-1 {
+	if p.position < p.total()-1 {
 		p.position++
 	}
 }
@@ -196,19 +374,19 @@ func (p *Player) Seek(position int) {
 	if position < 0 {
 		position = 0
 	}
-	if position >= len(p.events) {
-		position = len(p.events) - 1
+	if position >= p.total() {
+		position = p.total() - 1
 	}
 
-	if len(p.events) == 0 {
+	if p.total() == 0 {
 		position = 0
 	}
 
 	p.position = position
 
 	// If playing, reset startTime to new position
-	if p.playing && len(p.events) > 0 {
-		virtualElapsed := p.events[p.position].Ts.Sub(p.baseTime)
+	if p.playing && p.total() > 0 {
+		virtualElapsed := p.tsAt(p.position).Sub(p.baseTime)
 		scaledElapsed := time.Duration(float64(virtualElapsed) / p.speed)
 		p.startTime = time.Now().Add(-scaledElapsed)
 	}
@@ -220,10 +398,10 @@ func (p *Player) CurrentEvent() *schema.CanonicalEvent {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	if p.position < 0 || p.position >= len(p.events) {
+	if p.position < 0 || p.position >= p.total() {
 		return nil
 	}
-	return &p.events[p.position]
+	return p.eventAt(p.position)
 }
 
 // Position returns the current position (0-indexed).
@@ -237,7 +415,7 @@ func (p *Player) Position() int {
 func (p *Player) Total() int {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-	return len(p.events)
+	return p.total()
 }
 
 // IsPlaying returns true if playback is active.
@@ -261,7 +439,7 @@ func (p *Player) EventsUntil(now time.Time) []schema.CanonicalEvent {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	if !p.playing || len(p.events) == 0 {
+	if !p.playing || p.total() == 0 {
 		return nil
 	}
 
@@ -272,12 +450,149 @@ func (p *Player) EventsUntil(now time.Time) []schema.CanonicalEvent {
 
 	// Find all events before virtualNow
 	var result []schema.CanonicalEvent
-	for i := 0; i < len(p.events); i++ {
-		if p.events[i].Ts.After(virtualNow) {
+	for i := 0; i < p.total(); i++ {
+		if p.tsAt(i).After(virtualNow) {
 			break
 		}
-		result = append(result, p.events[i])
+		if evt := p.eventAt(i); evt != nil {
+			result = append(result, *evt)
+		}
+	}
+
+	return result
+}
+
+// SetMaxGap caps how long Advance waits, in virtual time, for the next
+// event: longer idle gaps are skipped down to d. Zero disables the cap.
+// It is SetIdleCompression(d, d).
+func (p *Player) SetMaxGap(d time.Duration) {
+	p.SetIdleCompression(d, d)
+}
+
+// Advance moves the playback clock to the real time now, scaled by the
+// speed, and returns the events that became due since the previous call,
+// in order. It returns nil while paused. Playback pauses itself at the
+// end of the range, or starts over from the range start when looping, in
+// which case Cursor moves back. Unlike EventsUntil, Advance hands each
+// event out once, so the caller can apply them incrementally.
+func (p *Player) Advance(now time.Time) []schema.CanonicalEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.playing {
+		return nil
+	}
+
+	p.compressIdle()
+	if !p.lastTick.IsZero() && now.After(p.lastTick) {
+		elapsed := now.Sub(p.lastTick)
+		p.vnow = p.vnow.Add(time.Duration(float64(elapsed) * p.speed))
+	}
+	p.lastTick = now
+
+	end := p.end()
+	var due []schema.CanonicalEvent
+	for p.delivered < end && !p.tsAt(p.delivered).After(p.vnow) {
+		if evt := p.eventAt(p.delivered); evt != nil {
+			due = append(due, *evt)
+		}
+		p.delivered++
+	}
+	if p.delivered > 0 {
+		p.position = p.delivered - 1
+	}
+	if p.delivered >= end {
+		if p.loop && end > p.rangeFrom {
+			p.setCursor(p.rangeFrom)
+			p.lastTick = now
+		} else {
+			p.playing = false
+		}
+	}
+	p.compressIdle()
+	return due
+}
+
+// compressIdle skips the virtual clock ahead when the gap before the next
+// event is longer than idleGap, so the remaining wait is at most idleTo.
+// The caller must hold p.mu.
+func (p *Player) compressIdle() {
+	if p.idleGap <= 0 || p.delivered >= p.total() {
+		return
+	}
+	prev := p.vnow
+	if p.delivered > 0 {
+		prev = p.tsAt(p.delivered - 1)
+	}
+	next := p.tsAt(p.delivered)
+	if next.Sub(prev) > p.idleGap && next.Sub(p.vnow) > p.idleTo {
+		p.vnow = next.Add(-p.idleTo)
+	}
+}
+
+// Cursor returns how many events have been delivered: events
+// [0, Cursor()) are the ones the viewer has seen.
+func (p *Player) Cursor() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.delivered
+}
+
+// SetCursor marks events [0, n) as delivered and moves the playback clock
+// to the timestamp of the last of them, so Advance resumes from there.
+func (p *Player) SetCursor(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setCursor(n)
+}
+
+// setCursor implements SetCursor. The caller must hold p.mu.
+func (p *Player) setCursor(n int) {
+	total := p.total()
+	if n < 0 {
+		n = 0
 	}
+	if n > total {
+		n = total
+	}
+	p.delivered = n
+	p.lastTick = time.Time{}
+	switch {
+	case n > 0:
+		p.position = n - 1
+		p.vnow = p.tsAt(n - 1)
+	case total > 0:
+		p.position = 0
+		p.vnow = p.tsAt(0)
+	default:
+		p.position = 0
+		p.vnow = time.Time{}
+	}
+}
+
+// Events returns events [from, to), clamped to the loaded range.
+func (p *Player) Events(from, to int) []schema.CanonicalEvent {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 
+	if from < 0 {
+		from = 0
+	}
+	if to > p.total() {
+		to = p.total()
+	}
+	var result []schema.CanonicalEvent
+	for i := from; i < to; i++ {
+		if evt := p.eventAt(i); evt != nil {
+			result = append(result, *evt)
+		}
+	}
 	return result
 }
+
+// VirtualTime returns the session time playback has reached.
+func (p *Player) VirtualTime() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.vnow
+}
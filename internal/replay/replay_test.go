@@ -1,6 +1,7 @@
 package replay
 
 import (
+	"compress/gzip"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -484,3 +485,108 @@ func TestEventsUntil_EmptyEvents(t *testing.T) {
 		t.Errorf("expected nil for empty events, got %d events", len(result))
 	}
 }
+
+func TestLoadFile_Compressed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl.gz")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	zw := gzip.NewWriter(f)
+	for i := 0; i < 3; i++ {
+		evt := schema.CanonicalEvent{
+			Ts:       time.Date(2026, 2, 17, 22, 27, i, 0, time.UTC),
+			RunID:    "run-1",
+			Provider: "claude",
+			AgentID:  "a1",
+			Role:     "executor",
+			State:    "running",
+			Type:     "message",
+		}
+		data, _ := json.Marshal(evt)
+		_, _ = zw.Write(append(data, '\n'))
+	}
+	_ = zw.Close()
+	_ = f.Close()
+
+	player := NewPlayer()
+	if err := player.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if player.Total() != 3 {
+		t.Errorf("expected 3 events, got %d", player.Total())
+	}
+}
+
+func TestAdvance_IncrementalClock(t *testing.T) {
+	base := time.Date(2026, 2, 17, 22, 27, 0, 0, time.UTC)
+	var events []schema.CanonicalEvent
+	for _, offset := range []time.Duration{0, time.Second, 2 * time.Second, time.Minute} {
+		events = append(events, schema.CanonicalEvent{
+			Ts: base.Add(offset), RunID: "run-1", Provider: "claude",
+			AgentID: "a1", Role: "executor", State: "running", Type: "message",
+		})
+	}
+	path := createTestJSONL(t, t.TempDir(), events)
+
+	player := NewPlayer()
+	if err := player.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	player.SetSpeed(2)
+	player.SetMaxGap(2 * time.Second)
+
+	if got := player.Advance(time.Now()); got != nil {
+		t.Errorf("expected nothing while paused, got %d", len(got))
+	}
+
+	player.Play()
+	start := time.Now()
+	if got := player.Advance(start); len(got) != 1 {
+		t.Fatalf("expected first event immediately, got %d", len(got))
+	}
+	// 1s real at 2x = 2s virtual
+	if got := player.Advance(start.Add(time.Second)); len(got) != 2 {
+		t.Errorf("expected 2 more events, got %d", len(got))
+	}
+	if player.Cursor() != 3 || player.Position() != 2 {
+		t.Errorf("expected cursor 3 at position 2, got %d at %d", player.Cursor(), player.Position())
+	}
+
+	// The 58s gap is capped to 2s virtual = 1s real
+	if got := player.Advance(start.Add(1500 * time.Millisecond)); len(got) != 0 {
+		t.Errorf("expected to wait inside the capped gap, got %d", len(got))
+	}
+	if got := player.Advance(start.Add(2 * time.Second)); len(got) != 1 {
+		t.Errorf("expected last event after capped gap, got %d", len(got))
+	}
+	if player.IsPlaying() {
+		t.Error("expected playback to pause after the last event")
+	}
+}
+
+func TestSetCursor(t *testing.T) {
+	path := createTestJSONL(t, t.TempDir(), []schema.CanonicalEvent{
+		{Ts: time.Date(2026, 2, 17, 22, 27, 0, 0, time.UTC), RunID: "r", Provider: "claude", AgentID: "a1", Role: "executor", State: "running", Type: "message"},
+		{Ts: time.Date(2026, 2, 17, 22, 27, 5, 0, time.UTC), RunID: "r", Provider: "claude", AgentID: "a2", Role: "executor", State: "running", Type: "message"},
+	})
+	player := NewPlayer()
+	if err := player.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	player.SetCursor(1)
+	if !player.VirtualTime().Equal(time.Date(2026, 2, 17, 22, 27, 0, 0, time.UTC)) {
+		t.Errorf("expected virtual time at first event, got %v", player.VirtualTime())
+	}
+	if got := player.Events(player.Cursor(), player.Total()); len(got) != 1 || got[0].AgentID != "a2" {
+		t.Errorf("expected remaining event a2, got %+v", got)
+	}
+
+	player.SetCursor(99)
+	if player.Cursor() != 2 {
+		t.Errorf("expected cursor clamped to 2, got %d", player.Cursor())
+	}
+}
@@ -0,0 +1,71 @@
+package replay
+
+import (
+	"fmt"
+)
+
+// LineError describes a line skipped while loading in tolerant mode.
+type LineError struct {
+	Path   string
+	Line   int
+	Reason string
+}
+
+// String formats the error as "path:line: reason".
+func (e LineError) String() string {
+	return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Reason)
+}
+
+// LoadReport summarizes the most recent load.
+type LoadReport struct {
+	Files   int
+	Events  int
+	Skipped []LineError
+}
+
+// SetTolerant selects how loads treat invalid lines. By default the first
+// invalid JSON line or event fails the load; in tolerant mode such lines
+// are skipped and listed in Report, and a compressed file that ends
+// mid-stream keeps the events read so far.
+func (p *Player) SetTolerant(tolerant bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tolerant = tolerant
+}
+
+// Report returns the report of the most recent successful load.
+func (p *Player) Report() LoadReport {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	report := p.report
+	report.Skipped = append([]LineError(nil), p.report.Skipped...)
+	return report
+}
+
+// isTolerant returns the tolerant setting.
+func (p *Player) isTolerant() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.tolerant
+}
+
+// skipper handles invalid lines while reading one file: in strict mode a
+// bad line becomes the load error, in tolerant mode it is recorded.
+type skipper struct {
+	path     string
+	tolerant bool
+	skipped  []LineError
+}
+
+// skip reports a bad line, returning the error to abort with, if any.
+func (s *skipper) skip(line int, reason string, err error) error {
+	if !s.tolerant {
+		return fmt.Errorf("line %d: %s: %w", line, reason, err)
+	}
+	s.skipped = append(s.skipped, LineError{
+		Path:   s.path,
+		Line:   line,
+		Reason: fmt.Sprintf("%s: %v", reason, err),
+	})
+	return nil
+}
@@ -0,0 +1,137 @@
+package replay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// damagedJSONL returns two valid events around an invalid event and ends
+// with a truncated line, as left by a crashed session.
+func damagedJSONL(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for i := 0; i < 2; i++ {
+		data, _ := json.Marshal(schema.CanonicalEvent{
+			Ts: time.Date(2026, 2, 17, 22, 27, i, 0, time.UTC), RunID: "run-1", Provider: "claude",
+			AgentID: "a1", Role: "executor", State: "running", Type: "message",
+		})
+		buf.Write(data)
+		buf.WriteByte('\n')
+		if i == 0 {
+			buf.WriteString(`{"ts":"2026-02-17T22:27:00Z","run_id":"run-1"}` + "\n")
+		}
+	}
+	buf.WriteString(`{"ts":"2026-02-17T22:27:09Z","run_`)
+	return buf.Bytes()
+}
+
+func TestLoadFile_Tolerant(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crashed.jsonl")
+	if err := os.WriteFile(path, damagedJSONL(t), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	strict := NewPlayer()
+	if err := strict.LoadFile(path); err == nil {
+		t.Error("expected strict loading to fail")
+	}
+
+	for _, stream := range []bool{false, true} {
+		player := NewPlayer()
+		player.SetTolerant(true)
+		load := player.LoadFile
+		if stream {
+			load = player.LoadStream
+		}
+		if err := load(path); err != nil {
+			t.Fatalf("tolerant load (stream=%v) failed: %v", stream, err)
+		}
+
+		report := player.Report()
+		if player.Total() != 2 || report.Events != 2 {
+			t.Errorf("stream=%v: expected 2 events, got %d", stream, player.Total())
+		}
+		if len(report.Skipped) != 2 {
+			t.Fatalf("stream=%v: expected 2 skipped lines, got %+v", stream, report.Skipped)
+		}
+		if got := report.Skipped[0]; got.Line != 2 || !strings.HasPrefix(got.Reason, "invalid event") {
+			t.Errorf("stream=%v: unexpected first skip %s", stream, got)
+		}
+		if got := report.Skipped[1]; got.Line != 4 || got.Path != path || !strings.HasPrefix(got.Reason, "invalid JSON") {
+			t.Errorf("stream=%v: unexpected second skip %s", stream, got)
+		}
+		_ = player.Close()
+	}
+}
+
+func TestLoadFile_TolerantTruncatedGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	for i := 0; i < 50; i++ {
+		data, _ := json.Marshal(schema.CanonicalEvent{
+			Ts: time.Date(2026, 2, 17, 22, 27, i, 0, time.UTC), RunID: "run-1", Provider: "claude",
+			AgentID: "a1", Role: "executor", State: "running", Type: "message",
+		})
+		_, _ = zw.Write(append(data, '\n'))
+	}
+	_ = zw.Close()
+
+	path := filepath.Join(t.TempDir(), "crashed.jsonl.gz")
+	if err := os.WriteFile(path, buf.Bytes()[:buf.Len()-10], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	player := NewPlayer()
+	player.SetTolerant(true)
+	if err := player.LoadFile(path); err != nil {
+		t.Fatalf("tolerant load failed: %v", err)
+	}
+	report := player.Report()
+	if player.Total() == 0 || len(report.Skipped) == 0 {
+		t.Fatalf("expected events before the cut and a truncation entry, got %d events, %+v", player.Total(), report.Skipped)
+	}
+	if last := report.Skipped[len(report.Skipped)-1]; !strings.HasPrefix(last.Reason, "truncated") {
+		t.Errorf("expected a truncation entry last, got %s", last)
+	}
+}
+
+func TestLoadFile_LongLine(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; i < 2; i++ {
+		evt := schema.CanonicalEvent{
+			Ts: time.Date(2026, 2, 17, 22, 27, i, 0, time.UTC), RunID: "run-1", Provider: "claude",
+			AgentID: "a1", Role: "executor", State: "running", Type: "message",
+		}
+		if i == 0 {
+			// Larger than bufio.Scanner's default 64KB token limit
+			evt.Payload = json.RawMessage(`{"output":"` + strings.Repeat("x", 200*1024) + `"}`)
+		}
+		data, _ := json.Marshal(evt)
+		buf.Write(append(data, '\n'))
+	}
+	path := filepath.Join(t.TempDir(), "long.jsonl")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tolerant := range []bool{false, true} {
+		player := NewPlayer()
+		player.SetTolerant(tolerant)
+		if err := player.LoadFile(path); err != nil {
+			t.Fatalf("tolerant=%v: load failed: %v", tolerant, err)
+		}
+		if player.Total() != 2 || len(player.Report().Skipped) != 0 {
+			t.Errorf("tolerant=%v: expected 2 events and no skips, got %d, %+v",
+				tolerant, player.Total(), player.Report().Skipped)
+		}
+		_ = player.Close()
+	}
+}
@@ -0,0 +1,187 @@
+package replay
+
+import (
+	"bufio"
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/internal/codec"
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// streamCacheSize is how many decoded events a stream keeps in memory.
+const streamCacheSize = 4096
+
+// indexEntry locates one event in a streamed file.
+type indexEntry struct {
+	offset int64
+	length int32
+	zone   int32 // UTC offset of ts in seconds, as written in the file
+	ts     int64 // unix nanoseconds
+}
+
+// stream serves events from an uncompressed JSONL file on demand.
+// Only a compact index (offset, length, timestamp per event) and a small
+// LRU cache of decoded events are held in memory.
+type stream struct {
+	f       *os.File
+	index   []indexEntry // sorted by timestamp
+	skipped []LineError  // lines left out in tolerant mode
+
+	mu    sync.Mutex
+	cache map[int]*list.Element
+	lru   *list.List // front = most recently used
+}
+
+// cached is an LRU entry.
+type cached struct {
+	pos   int
+	event schema.CanonicalEvent
+}
+
+// openStream indexes path in a single pass, validating every event, and
+// returns a stream sorted by timestamp. In tolerant mode invalid lines
+// are left out and recorded instead of failing. Compressed files cannot
+// be streamed because they do not support random access.
+func openStream(path string, tolerant bool) (*stream, error) {
+	if codec.IsCompressed(path) {
+		return nil, fmt.Errorf("streaming %s: compressed files cannot be streamed, decompress first", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+
+	sk := &skipper{path: path, tolerant: tolerant}
+	index, err := buildIndex(f, sk)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return &stream{
+		f:       f,
+		index:   index,
+		skipped: sk.skipped,
+		cache:   make(map[int]*list.Element),
+		lru:     list.New(),
+	}, nil
+}
+
+// buildIndex scans every line of r once, recording where each event is
+// and when it happened. Invalid lines are passed to sk.
+func buildIndex(r io.Reader, sk *skipper) ([]indexEntry, error) {
+	var index []indexEntry
+	reader := bufio.NewReaderSize(r, 64*1024)
+	var offset int64
+	lineNum := 0
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			lineNum++
+			start := offset
+			offset += int64(len(line))
+
+			trimmed := trimNewline(line)
+			if len(trimmed) > 0 {
+				evt, reason, err := decodeLine(trimmed)
+				if err != nil {
+					if err := sk.skip(lineNum, reason, err); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				_, zone := evt.Ts.Zone()
+				index = append(index, indexEntry{
+					offset: start,
+					length: int32(len(trimmed)),
+					zone:   int32(zone),
+					ts:     evt.Ts.UnixNano(),
+				})
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("scan file: %w", err)
+		}
+	}
+
+	sort.SliceStable(index, func(i, j int) bool {
+		return index[i].ts < index[j].ts
+	})
+	return index, nil
+}
+
+// trimNewline strips a trailing "\n" or "\r\n".
+func trimNewline(line []byte) []byte {
+	n := len(line)
+	if n > 0 && line[n-1] == '\n' {
+		n--
+	}
+	if n > 0 && line[n-1] == '\r' {
+		n--
+	}
+	return line[:n]
+}
+
+// Len returns the number of events.
+func (s *stream) Len() int {
+	return len(s.index)
+}
+
+// Ts returns the timestamp of event i without reading it from disk. It
+// keeps the offset the file gave, like events loaded into memory.
+func (s *stream) Ts(i int) time.Time {
+	entry := s.index[i]
+	ts := time.Unix(0, entry.ts)
+	if entry.zone == 0 {
+		return ts.UTC()
+	}
+	return ts.In(time.FixedZone("", int(entry.zone)))
+}
+
+// Event reads and decodes event i, serving recently used events from
+// the cache.
+func (s *stream) Event(i int) (schema.CanonicalEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.cache[i]; ok {
+		s.lru.MoveToFront(elem)
+		return elem.Value.(*cached).event, nil
+	}
+
+	entry := s.index[i]
+	buf := make([]byte, entry.length)
+	if _, err := s.f.ReadAt(buf, entry.offset); err != nil {
+		return schema.CanonicalEvent{}, fmt.Errorf("read event %d: %w", i, err)
+	}
+	var evt schema.CanonicalEvent
+	if err := json.Unmarshal(buf, &evt); err != nil {
+		return schema.CanonicalEvent{}, fmt.Errorf("decode event %d: %w", i, err)
+	}
+
+	s.cache[i] = s.lru.PushFront(&cached{pos: i, event: evt})
+	if s.lru.Len() > streamCacheSize {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.cache, oldest.Value.(*cached).pos)
+	}
+	return evt, nil
+}
+
+// Close releases the underlying file.
+func (s *stream) Close() error {
+	return s.f.Close()
+}
@@ -0,0 +1,137 @@
+package replay
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+func streamEvents(n int) []schema.CanonicalEvent {
+	base := time.Date(2026, 2, 17, 22, 27, 0, 0, time.UTC)
+	events := make([]schema.CanonicalEvent, n)
+	for i := range events {
+		// Written in reverse so the index must sort them
+		events[i] = schema.CanonicalEvent{
+			Ts:       base.Add(time.Duration(n-1-i) * time.Second),
+			RunID:    "run-1",
+			Provider: "claude",
+			AgentID:  fmt.Sprintf("agent-%d", n-1-i),
+			Role:     "executor",
+			State:    "running",
+			Type:     "message",
+		}
+	}
+	return events
+}
+
+func TestLoadStream(t *testing.T) {
+	path := createTestJSONL(t, t.TempDir(), streamEvents(5000))
+
+	player := NewPlayer()
+	if err := player.LoadStream(path); err != nil {
+		t.Fatalf("LoadStream failed: %v", err)
+	}
+	defer func() { _ = player.Close() }()
+
+	if !player.Streaming() {
+		t.Error("expected streaming mode")
+	}
+	if player.Total() != 5000 {
+		t.Fatalf("expected 5000 events, got %d", player.Total())
+	}
+	if evt := player.CurrentEvent(); evt == nil || evt.AgentID != "agent-0" {
+		t.Errorf("expected earliest event first, got %+v", evt)
+	}
+
+	// Walk past the cache size and back to force evictions and re-reads
+	player.Seek(4999)
+	if evt := player.CurrentEvent(); evt == nil || evt.AgentID != "agent-4999" {
+		t.Errorf("expected agent-4999 at the end, got %+v", evt)
+	}
+	for i := 0; i < 10; i++ {
+		player.StepBackward()
+	}
+	if evt := player.CurrentEvent(); evt == nil || evt.AgentID != "agent-4989" {
+		t.Errorf("expected agent-4989 after stepping back, got %+v", evt)
+	}
+	player.Seek(1)
+	if evt := player.CurrentEvent(); evt == nil || evt.AgentID != "agent-1" {
+		t.Errorf("expected agent-1 after seek, got %+v", evt)
+	}
+
+	player.SetSpeed(16)
+	player.Play()
+	got := player.EventsUntil(time.Now())
+	if len(got) < 2 || got[1].AgentID != "agent-1" {
+		t.Errorf("expected events up to the current position, got %d", len(got))
+	}
+}
+
+func TestLoadFile_FallsBackToStreaming(t *testing.T) {
+	path := createTestJSONL(t, t.TempDir(), streamEvents(10))
+
+	old := maxFileSize
+	maxFileSize = 100
+	defer func() { maxFileSize = old }()
+
+	player := NewPlayer()
+	if err := player.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	defer func() { _ = player.Close() }()
+
+	if !player.Streaming() || player.Total() != 10 {
+		t.Errorf("expected 10 streamed events, got streaming=%v total=%d", player.Streaming(), player.Total())
+	}
+}
+
+func TestLoadStream_KeepsOffset(t *testing.T) {
+	events := streamEvents(3)
+	kst := time.FixedZone("KST", 9*60*60)
+	for i := range events {
+		events[i].Ts = events[i].Ts.In(kst)
+	}
+	path := createTestJSONL(t, t.TempDir(), events)
+
+	memory := NewPlayer()
+	if err := memory.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	defer func() { _ = memory.Close() }()
+	streamed := NewPlayer()
+	if err := streamed.LoadStream(path); err != nil {
+		t.Fatalf("LoadStream failed: %v", err)
+	}
+	defer func() { _ = streamed.Close() }()
+
+	for i := 0; i < 3; i++ {
+		want := memory.tsAt(i).Format(time.RFC3339Nano)
+		if got := streamed.tsAt(i).Format(time.RFC3339Nano); got != want {
+			t.Errorf("event %d ts = %s, want %s as loaded in memory", i, got, want)
+		}
+	}
+}
+
+func TestLoadStream_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	gz := filepath.Join(dir, "big.jsonl.gz")
+	if err := os.WriteFile(gz, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewPlayer().LoadStream(gz); err == nil {
+		t.Error("expected error streaming a compressed file")
+	}
+
+	bad := filepath.Join(dir, "bad.jsonl")
+	if err := os.WriteFile(bad, []byte("{not json}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewPlayer().LoadStream(bad); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
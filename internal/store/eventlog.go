@@ -0,0 +1,114 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// EventLog is a durable, append-only record of every event added to a
+// Store. The ring buffer keeps only the most recent events; an EventLog
+// keeps the full history so it survives restarts and can be queried long
+// after events have been evicted from memory.
+type EventLog interface {
+	// Append persists a single event.
+	Append(event schema.CanonicalEvent) error
+	// Scan calls fn for each persisted event with since <= Ts < until, in
+	// append order, until fn returns false. Zero bounds are open.
+	Scan(since, until time.Time, fn func(schema.CanonicalEvent) bool) error
+	// Close flushes and releases the log.
+	Close() error
+}
+
+// NewStoreWithLog creates a Store backed by an EventLog. Events already in
+// the log are replayed first, so agents, tasks, runs and metrics are
+// restored exactly as they were before the previous shutdown.
+func NewStoreWithLog(maxEvents int, history EventLog) (*Store, error) {
+	s := NewStore(maxEvents)
+
+	err := history.Scan(time.Time{}, time.Time{}, func(event schema.CanonicalEvent) bool {
+		s.add(event)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("restore from event log: %w", err)
+	}
+
+	s.history = history
+	return s, nil
+}
+
+// Open creates a Store persisted to a segment log in dir, restoring any
+// history already there.
+func Open(dir string, maxEvents int) (*Store, error) {
+	history, err := OpenSegmentLog(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewStoreWithLog(maxEvents, history)
+	if err != nil {
+		_ = history.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Persistent reports whether the store has a durable EventLog attached.
+func (s *Store) Persistent() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.history != nil
+}
+
+// History queries the full event history, including events already
+// evicted from the ring buffer. Without an EventLog it is equivalent to
+// Query. Results are chronological; Limit keeps the most recent matches.
+func (s *Store) History(f Filter) ([]schema.CanonicalEvent, error) {
+	s.mu.RLock()
+	history := s.history
+	if len(f.RunIDs) == 0 && s.selected != "" {
+		f.RunIDs = []string{s.selected}
+	}
+	if history == nil {
+		defer s.mu.RUnlock()
+		return s.query(f), nil
+	}
+	s.mu.RUnlock()
+
+	text := strings.ToLower(f.Text)
+	var result []schema.CanonicalEvent
+	err := history.Scan(f.Since, f.Until, func(event schema.CanonicalEvent) bool {
+		if !matches(event, f, text) {
+			return true
+		}
+		result = append(result, event)
+		if f.Limit > 0 && len(result) > 2*f.Limit {
+			// Bound memory while scanning hours of history.
+			result = append(result[:0], result[len(result)-f.Limit:]...)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan event log: %w", err)
+	}
+
+	if f.Limit > 0 && len(result) > f.Limit {
+		result = result[len(result)-f.Limit:]
+	}
+	return result, nil
+}
+
+// Close releases the EventLog, if any. The in-memory state stays usable.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.history == nil {
+		return nil
+	}
+	err := s.history.Close()
+	s.history = nil
+	return err
+}
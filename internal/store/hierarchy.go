@@ -0,0 +1,157 @@
+package store
+
+import "sort"
+
+// AgentNode is an agent and its spawned sub-agents.
+type AgentNode struct {
+	Agent    *AgentInfo
+	Depth    int // 0 for root agents
+	Children []AgentNode
+}
+
+// GetChildren returns the agents spawned by agentID, in the order they
+// were first seen.
+func (s *Store) GetChildren(agentID string) []*AgentInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := s.view()
+	result := make([]*AgentInfo, 0, len(st.children[agentID]))
+	for _, id := range st.children[agentID] {
+		result = append(result, st.agents[id].clone())
+	}
+	return result
+}
+
+// GetAncestors returns the chain of agents above agentID, nearest parent
+// first. The chain stops at a parent the Store has not seen.
+func (s *Store) GetAncestors(agentID string) []*AgentInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneAgents(s.view().ancestors(agentID))
+}
+
+// GetRootAgents returns agents without a known parent, ordered by first
+// appearance.
+func (s *Store) GetRootAgents() []*AgentInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneAgents(s.view().roots())
+}
+
+// GetDepth returns how many known ancestors agentID has (0 for roots).
+func (s *Store) GetDepth(agentID string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.view().ancestors(agentID))
+}
+
+// GetFanOut returns the number of agents spawned directly by agentID.
+func (s *Store) GetFanOut(agentID string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.view().children[agentID])
+}
+
+// GetSubtreeMetrics returns metrics rolled up over agentID and every agent
+// below it.
+func (s *Store) GetSubtreeMetrics(agentID string) Metrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := s.view()
+	var total Metrics
+	st.walk(agentID, func(id string) {
+		if m, ok := st.agentMetrics[id]; ok {
+			total.EventCount += m.EventCount
+			total.ErrorCount += m.ErrorCount
+			total.TotalLatency += m.TotalLatency
+			total.TotalTokensIn += m.TotalTokensIn
+			total.TotalTokensOut += m.TotalTokensOut
+			total.TotalCostUSD += m.TotalCostUSD
+		}
+	})
+	return total
+}
+
+// GetAgentTree returns the full spawn tree, one node per root agent.
+func (s *Store) GetAgentTree() []AgentNode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := s.view()
+	roots := st.roots()
+	result := make([]AgentNode, 0, len(roots))
+	for _, root := range roots {
+		result = append(result, st.node(root.AgentID, 0))
+	}
+	return result
+}
+
+// ancestors returns the known parents of agentID, nearest first.
+func (st *runState) ancestors(agentID string) []*AgentInfo {
+	var result []*AgentInfo
+	agent, ok := st.agents[agentID]
+	for ok && agent.ParentAgentID != "" {
+		agent, ok = st.agents[agent.ParentAgentID]
+		if ok {
+			result = append(result, agent)
+		}
+	}
+	return result
+}
+
+// roots returns agents whose parent is unset or unknown.
+func (st *runState) roots() []*AgentInfo {
+	var result []*AgentInfo
+	for _, agent := range st.agents {
+		if _, ok := st.agents[agent.ParentAgentID]; !ok {
+			result = append(result, agent)
+		}
+	}
+	sortByFirstSeen(result)
+	return result
+}
+
+// walk calls fn for agentID and every agent below it, depth first.
+func (st *runState) walk(agentID string, fn func(id string)) {
+	fn(agentID)
+	for _, child := range st.children[agentID] {
+		st.walk(child, fn)
+	}
+}
+
+// node builds the subtree rooted at agentID.
+func (st *runState) node(agentID string, depth int) AgentNode {
+	n := AgentNode{Agent: st.agents[agentID].clone(), Depth: depth}
+	for _, child := range st.children[agentID] {
+		n.Children = append(n.Children, st.node(child, depth+1))
+	}
+	return n
+}
+
+// relink rebuilds the children index from each agent's ParentAgentID,
+// ordering siblings by first appearance.
+func (st *runState) relink() {
+	st.children = make(map[string][]string)
+	agents := make([]*AgentInfo, 0, len(st.agents))
+	for _, agent := range st.agents {
+		if agent.ParentAgentID != "" {
+			agents = append(agents, agent)
+		}
+	}
+	sortByFirstSeen(agents)
+	for _, agent := range agents {
+		st.children[agent.ParentAgentID] = append(st.children[agent.ParentAgentID], agent.AgentID)
+	}
+}
+
+// sortByFirstSeen orders agents by first appearance, then by ID.
+func sortByFirstSeen(agents []*AgentInfo) {
+	sort.Slice(agents, func(i, j int) bool {
+		if agents[i].FirstSeen.Equal(agents[j].FirstSeen) {
+			return agents[i].AgentID < agents[j].AgentID
+		}
+		return agents[i].FirstSeen.Before(agents[j].FirstSeen)
+	})
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// hierarchyFixture builds orchestrator -> {planner -> coder, reviewer}.
+func hierarchyFixture() *Store {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+	links := []struct{ agent, parent string }{
+		{"orchestrator", ""},
+		{"planner", "orchestrator"},
+		{"reviewer", "orchestrator"},
+		{"coder", "planner"},
+	}
+	for i, link := range links {
+		// one second apart, costing $1, $2, $3 and $4
+		e := testEvent("run-1", link.agent, schema.TypeTaskSpawn, schema.StateRunning, base.Add(time.Duration(i)*time.Second))
+		e.ParentAgentID = link.parent
+		cost := float64(i + 1)
+		e.Metrics = &schema.EventMetrics{CostUSD: &cost}
+		store.AddEvent(e)
+	}
+	return store
+}
+
+func agentIDs(agents []*AgentInfo) []string {
+	ids := make([]string, len(agents))
+	for i, a := range agents {
+		ids[i] = a.AgentID
+	}
+	return ids
+}
+
+func TestAgentHierarchy(t *testing.T) {
+	store := hierarchyFixture()
+
+	if got := agentIDs(store.GetChildren("orchestrator")); len(got) != 2 || got[0] != "planner" || got[1] != "reviewer" {
+		t.Errorf("expected children [planner reviewer], got %v", got)
+	}
+	if got := agentIDs(store.GetAncestors("coder")); len(got) != 2 || got[0] != "planner" || got[1] != "orchestrator" {
+		t.Errorf("expected ancestors [planner orchestrator], got %v", got)
+	}
+	if got := agentIDs(store.GetRootAgents()); len(got) != 1 || got[0] != "orchestrator" {
+		t.Errorf("expected root [orchestrator], got %v", got)
+	}
+	if got := store.GetDepth("coder"); got != 2 {
+		t.Errorf("expected depth 2, got %d", got)
+	}
+	if got := store.GetFanOut("orchestrator"); got != 2 {
+		t.Errorf("expected fan-out 2, got %d", got)
+	}
+	if got := store.GetSubtreeMetrics("planner"); got.TotalCostUSD != 6 || got.EventCount != 2 {
+		t.Errorf("expected planner subtree cost 6 over 2 events, got %+v", got)
+	}
+	if got := store.GetSubtreeMetrics("orchestrator"); got.TotalCostUSD != 10 {
+		t.Errorf("expected full tree cost 10, got %v", got.TotalCostUSD)
+	}
+
+	tree := store.GetAgentTree()
+	if len(tree) != 1 || len(tree[0].Children) != 2 || tree[0].Children[0].Children[0].Depth != 2 {
+		t.Errorf("unexpected tree shape: %+v", tree)
+	}
+}
+
+func TestAgentHierarchy_CycleGuard(t *testing.T) {
+	store := hierarchyFixture()
+
+	// orchestrator claiming coder as parent would close a loop
+	e := testEvent("run-1", "orchestrator", schema.TypeTaskSpawn, schema.StateRunning, time.Date(2026, 2, 17, 10, 0, 4, 0, time.UTC))
+	e.ParentAgentID = "coder"
+	store.AddEvent(e)
+	if got := store.GetAgent("orchestrator").ParentAgentID; got != "" {
+		t.Errorf("expected cyclic parent ignored, got %q", got)
+	}
+	if got := store.GetDepth("coder"); got != 2 {
+		t.Errorf("expected depth unchanged at 2, got %d", got)
+	}
+}
+
+func TestAgentHierarchy_UnknownParent(t *testing.T) {
+	store := NewStore(100)
+	e := testEvent("run-1", "worker", schema.TypeTaskSpawn, schema.StateRunning, time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC))
+	e.ParentAgentID = "main"
+	store.AddEvent(e)
+
+	if got := agentIDs(store.GetRootAgents()); len(got) != 1 || got[0] != "worker" {
+		t.Errorf("agent with unseen parent should be a root, got %v", got)
+	}
+	if got := store.GetFanOut("main"); got != 1 {
+		t.Errorf("expected unseen parent to still count its child, got %d", got)
+	}
+}
+
+func TestAgentHierarchy_SurvivesSnapshot(t *testing.T) {
+	var buf bytes.Buffer
+	if err := hierarchyFixture().Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore(100)
+	if err := store.Restore(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := agentIDs(store.GetChildren("orchestrator")); len(got) != 2 || got[0] != "planner" {
+		t.Errorf("expected children rebuilt after restore, got %v", got)
+	}
+}
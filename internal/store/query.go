@@ -0,0 +1,229 @@
+package store
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// Filter selects events for Query.
+// Empty fields match everything. Values inside a slice field are OR-ed;
+// the fields themselves are AND-ed.
+type Filter struct {
+	RunIDs   []string // defaults to the selected run, if any
+	AgentIDs []string
+	Types    []schema.EventType
+	States   []schema.AgentState
+	TaskID   string
+	Since    time.Time // inclusive lower bound on Ts
+	Until    time.Time // exclusive upper bound on Ts
+	Text     string    // case-insensitive substring of IDs, type, state or payload
+	Limit    int       // keep only the most recent N matches; 0 means all
+}
+
+// Query returns retained events matching the filter in chronological
+// order (oldest first). Only events still held by the ring buffer are
+// considered. When a run is selected and f.RunIDs is empty, results are
+// limited to that run.
+func (s *Store) Query(f Filter) []schema.CanonicalEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.query(f)
+}
+
+// query implements Query; the caller must hold s.mu.
+func (s *Store) query(f Filter) []schema.CanonicalEvent {
+	if len(f.RunIDs) == 0 && s.selected != "" {
+		f.RunIDs = []string{s.selected}
+	}
+
+	text := strings.ToLower(f.Text)
+	candidates, indexed := s.candidates(f)
+
+	var result []schema.CanonicalEvent
+	collect := func(seq uint64) bool {
+		event := s.events[seq%uint64(s.maxEvents)]
+		if matches(event, f, text) {
+			result = append(result, event)
+		}
+		return f.Limit <= 0 || len(result) < f.Limit
+	}
+
+	// Walk newest to oldest so Limit keeps the most recent matches.
+	if indexed {
+		for i := len(candidates) - 1; i >= 0; i-- {
+			if !collect(candidates[i]) {
+				break
+			}
+		}
+	} else {
+		for seq := s.seq; seq > s.seq-uint64(s.count); seq-- {
+			if !collect(seq - 1) {
+				break
+			}
+		}
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// candidates returns the ascending sequence numbers from the most
+// selective index that applies to the filter. The second result is
+// false when no indexed field is set and the whole buffer must be scanned.
+func (s *Store) candidates(f Filter) ([]uint64, bool) {
+	var lists [][][]uint64
+
+	if len(f.RunIDs) > 0 {
+		var l [][]uint64
+		for _, id := range f.RunIDs {
+			l = append(l, s.byRun[id])
+		}
+		lists = append(lists, l)
+	}
+	if len(f.AgentIDs) > 0 {
+		var l [][]uint64
+		for _, id := range f.AgentIDs {
+			l = append(l, s.byAgent[id])
+		}
+		lists = append(lists, l)
+	}
+	if len(f.Types) > 0 {
+		var l [][]uint64
+		for _, t := range f.Types {
+			l = append(l, s.byType[t])
+		}
+		lists = append(lists, l)
+	}
+	if len(f.States) > 0 {
+		var l [][]uint64
+		for _, st := range f.States {
+			l = append(l, s.byState[st])
+		}
+		lists = append(lists, l)
+	}
+	if f.TaskID != "" {
+		lists = append(lists, [][]uint64{s.byTask[f.TaskID]})
+	}
+
+	if len(lists) == 0 {
+		return nil, false
+	}
+
+	best, bestSize := 0, -1
+	for i, group := range lists {
+		size := 0
+		for _, l := range group {
+			size += len(l)
+		}
+		if bestSize < 0 || size < bestSize {
+			best, bestSize = i, size
+		}
+	}
+
+	merged := make([]uint64, 0, bestSize)
+	for _, l := range lists[best] {
+		merged = append(merged, l...)
+	}
+	if len(lists[best]) > 1 {
+		sort.Slice(merged, func(i, j int) bool { return merged[i] < merged[j] })
+	}
+	return merged, true
+}
+
+// matches reports whether an event satisfies every field of the filter.
+// text must already be lower-cased.
+func matches(event schema.CanonicalEvent, f Filter, text string) bool {
+	if len(f.RunIDs) > 0 && !slices.Contains(f.RunIDs, event.RunID) {
+		return false
+	}
+	if len(f.AgentIDs) > 0 && !slices.Contains(f.AgentIDs, event.AgentID) {
+		return false
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, event.Type) {
+		return false
+	}
+	if len(f.States) > 0 && !slices.Contains(f.States, event.State) {
+		return false
+	}
+	if f.TaskID != "" && event.TaskID != f.TaskID {
+		return false
+	}
+	if !f.Since.IsZero() && event.Ts.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !event.Ts.Before(f.Until) {
+		return false
+	}
+	if text != "" && !matchesText(event, text) {
+		return false
+	}
+	return true
+}
+
+// matchesText performs a case-insensitive substring search over the
+// human-visible fields of an event.
+func matchesText(event schema.CanonicalEvent, text string) bool {
+	fields := []string{
+		event.AgentID,
+		event.ParentAgentID,
+		event.TaskID,
+		event.IntentRef,
+		string(event.Role),
+		string(event.Type),
+		string(event.State),
+		string(event.Payload),
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// index records a newly stored event in the secondary indexes.
+func (s *Store) index(seq uint64, event schema.CanonicalEvent) {
+	s.byAgent[event.AgentID] = append(s.byAgent[event.AgentID], seq)
+	s.byType[event.Type] = append(s.byType[event.Type], seq)
+	s.byState[event.State] = append(s.byState[event.State], seq)
+	if event.TaskID != "" {
+		s.byTask[event.TaskID] = append(s.byTask[event.TaskID], seq)
+	}
+	if event.RunID != "" {
+		s.byRun[event.RunID] = append(s.byRun[event.RunID], seq)
+	}
+}
+
+// unindex removes an evicted event from the secondary indexes.
+// The evicted event is always the oldest retained one, so it sits at the
+// head of each list it appears in.
+func (s *Store) unindex(seq uint64, event schema.CanonicalEvent) {
+	dropHead(s.byAgent, event.AgentID, seq)
+	dropHead(s.byType, event.Type, seq)
+	dropHead(s.byState, event.State, seq)
+	if event.TaskID != "" {
+		dropHead(s.byTask, event.TaskID, seq)
+	}
+	if event.RunID != "" {
+		dropHead(s.byRun, event.RunID, seq)
+	}
+}
+
+// dropHead removes seq from the front of idx[key], deleting empty keys.
+func dropHead[K comparable](idx map[K][]uint64, key K, seq uint64) {
+	list := idx[key]
+	if len(list) == 0 || list[0] != seq {
+		return
+	}
+	if len(list) == 1 {
+		delete(idx, key)
+		return
+	}
+	idx[key] = list[1:]
+}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+func TestQuery_Filters(t *testing.T) {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	errPayload, _ := json.Marshal(schema.ErrorPayload{ErrorType: "timeout", Message: "Deadline exceeded"})
+
+	events := []schema.CanonicalEvent{
+		testEvent("run-1", "agent-1", schema.TypeTaskSpawn, schema.StateRunning, base),
+		testEvent("run-1", "agent-2", schema.TypeTaskSpawn, schema.StateRunning, base.Add(1*time.Minute)),
+		testEvent("run-1", "agent-1", schema.TypeError, schema.StateError, base.Add(2*time.Minute)),
+		testEvent("run-1", "agent-2", schema.TypeTaskDone, schema.StateDone, base.Add(3*time.Minute)),
+		testEvent("run-1", "agent-1", schema.TypeRecover, schema.StateRunning, base.Add(4*time.Minute)),
+	}
+	events[2].Payload = errPayload
+	for _, e := range events {
+		// each agent works its own task: agent-1 on task-1, agent-2 on task-2
+		e.TaskID = "task-" + e.AgentID[len("agent-"):]
+		store.AddEvent(e)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"empty filter", Filter{}, 5},
+		{"by agent", Filter{AgentIDs: []string{"agent-1"}}, 3},
+		{"by agents", Filter{AgentIDs: []string{"agent-1", "agent-2"}}, 5},
+		{"by type", Filter{Types: []schema.EventType{schema.TypeTaskSpawn}}, 2},
+		{"by state", Filter{States: []schema.AgentState{schema.StateError, schema.StateDone}}, 2},
+		{"by task", Filter{TaskID: "task-2"}, 2},
+		{"agent and type", Filter{AgentIDs: []string{"agent-2"}, Types: []schema.EventType{schema.TypeTaskDone}}, 1},
+		{"since", Filter{Since: base.Add(2 * time.Minute)}, 3},
+		{"until", Filter{Until: base.Add(2 * time.Minute)}, 2},
+		{"time window", Filter{Since: base.Add(1 * time.Minute), Until: base.Add(3 * time.Minute)}, 2},
+		{"text in payload", Filter{Text: "deadline"}, 1},
+		{"text in id", Filter{Text: "AGENT-2"}, 2},
+		{"unknown agent", Filter{AgentIDs: []string{"nobody"}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := store.Query(tt.filter)
+			if len(got) != tt.want {
+				t.Errorf("expected %d events, got %d", tt.want, len(got))
+			}
+			for i := 1; i < len(got); i++ {
+				if got[i].Ts.Before(got[i-1].Ts) {
+					t.Errorf("results not chronological at %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestQuery_Limit(t *testing.T) {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 10; i++ {
+		store.AddEvent(testEvent("run-1", "agent-1", schema.TypeMessage, schema.StateRunning, base.Add(time.Duration(i)*time.Second)))
+	}
+
+	got := store.Query(Filter{AgentIDs: []string{"agent-1"}, Limit: 3})
+	if len(got) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(got))
+	}
+	if !got[0].Ts.Equal(base.Add(7*time.Second)) || !got[2].Ts.Equal(base.Add(9*time.Second)) {
+		t.Errorf("expected the 3 most recent events, got %v..%v", got[0].Ts, got[2].Ts)
+	}
+
+	got = store.Query(Filter{Limit: 2})
+	if len(got) != 2 || !got[1].Ts.Equal(base.Add(9*time.Second)) {
+		t.Errorf("unindexed limit should keep newest events, got %d", len(got))
+	}
+}
+
+func TestQuery_RingBufferEviction(t *testing.T) {
+	store := NewStore(5)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	// agent-old only appears in events that will be overwritten.
+	for i := 0; i < 3; i++ {
+		e := testEvent("run-1", "agent-old", schema.TypeMessage, schema.StateRunning, base.Add(time.Duration(i)*time.Second))
+		e.TaskID = "task-old"
+		store.AddEvent(e)
+	}
+	for i := 3; i < 10; i++ {
+		agent := "agent-a"
+		if i%2 == 0 {
+			agent = "agent-b"
+		}
+		store.AddEvent(testEvent("run-1", agent, schema.TypeMessage, schema.StateRunning, base.Add(time.Duration(i)*time.Second)))
+	}
+
+	if got := store.Query(Filter{AgentIDs: []string{"agent-old"}}); len(got) != 0 {
+		t.Errorf("evicted agent should have no events, got %d", len(got))
+	}
+	if got := store.Query(Filter{TaskID: "task-old"}); len(got) != 0 {
+		t.Errorf("evicted task should have no events, got %d", len(got))
+	}
+
+	// Retained: i=5..9 -> agent-a at 5,7,9 and agent-b at 6,8.
+	a := store.Query(Filter{AgentIDs: []string{"agent-a"}})
+	if len(a) != 3 {
+		t.Fatalf("expected 3 agent-a events, got %d", len(a))
+	}
+	if !a[0].Ts.Equal(base.Add(5 * time.Second)) {
+		t.Errorf("oldest agent-a event = %v, want +5s", a[0].Ts)
+	}
+	if got := store.Query(Filter{AgentIDs: []string{"agent-b"}}); len(got) != 2 {
+		t.Errorf("expected 2 agent-b events, got %d", len(got))
+	}
+	if got := store.Query(Filter{Types: []schema.EventType{schema.TypeMessage}}); len(got) != 5 {
+		t.Errorf("expected 5 retained events, got %d", len(got))
+	}
+	if _, ok := store.byAgent["agent-old"]; ok {
+		t.Error("empty index keys should be removed")
+	}
+}
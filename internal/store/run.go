@@ -0,0 +1,126 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// RunInfo summarizes a single run seen by the Store.
+type RunInfo struct {
+	RunID      string
+	Mode       schema.Mode
+	StartTime  time.Time // earliest event timestamp
+	EndTime    time.Time // latest event timestamp
+	AgentCount int
+	EventCount int
+}
+
+// run pairs a run's metadata with its own aggregates.
+type run struct {
+	info  RunInfo
+	state *runState
+}
+
+// trackRun returns the run an event belongs to, creating it on first
+// sight and extending its time span. Events without a RunID return nil.
+func (s *Store) trackRun(event schema.CanonicalEvent) *run {
+	if event.RunID == "" {
+		return nil
+	}
+
+	r, ok := s.runs[event.RunID]
+	if !ok {
+		r = &run{
+			info:  RunInfo{RunID: event.RunID, StartTime: event.Ts, EndTime: event.Ts},
+			state: newRunState(),
+		}
+		s.runs[event.RunID] = r
+	}
+
+	if event.Mode != "" {
+		r.info.Mode = event.Mode
+	}
+	if event.Ts.Before(r.info.StartTime) {
+		r.info.StartTime = event.Ts
+	}
+	if event.Ts.After(r.info.EndTime) {
+		r.info.EndTime = event.Ts
+	}
+	r.info.EventCount++
+	return r
+}
+
+// view returns the aggregates getters should read: the selected run's,
+// or the combined ones when no run is selected. The caller must hold s.mu.
+func (s *Store) view() *runState {
+	if s.selected != "" {
+		if r, ok := s.runs[s.selected]; ok {
+			return r.state
+		}
+	}
+	return s.all
+}
+
+// ListRuns returns every run seen so far, ordered by start time.
+func (s *Store) ListRuns() []RunInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]RunInfo, 0, len(s.runs))
+	for _, r := range s.runs {
+		result = append(result, r.snapshot())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].StartTime.Equal(result[j].StartTime) {
+			return result[i].RunID < result[j].RunID
+		}
+		return result[i].StartTime.Before(result[j].StartTime)
+	})
+	return result
+}
+
+// GetRun returns a summary of a single run, or nil if unknown.
+func (s *Store) GetRun(runID string) *RunInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.runs[runID]
+	if !ok {
+		return nil
+	}
+	info := r.snapshot()
+	return &info
+}
+
+// SelectRun scopes agent, task, metric, tool and event getters to a
+// single run. An empty runID clears the selection so getters cover all
+// runs again.
+func (s *Store) SelectRun(runID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if runID != "" {
+		if _, ok := s.runs[runID]; !ok {
+			return fmt.Errorf("unknown run: %q", runID)
+		}
+	}
+	s.selected = runID
+	return nil
+}
+
+// SelectedRun returns the run getters are scoped to, or "" for all runs.
+func (s *Store) SelectedRun() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.selected
+}
+
+// snapshot returns a copy of the run's metadata with derived counts filled in.
+func (r *run) snapshot() RunInfo {
+	info := r.info
+	info.AgentCount = len(r.state.agents)
+	return info
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+func TestListRuns(t *testing.T) {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	store.AddEvent(testEvent("run-b", "agent-1", schema.TypeMessage, schema.StateRunning, base.Add(time.Minute)))
+	first := testEvent("run-a", "agent-1", schema.TypeMessage, schema.StateRunning, base)
+	first.Mode = schema.ModeTeam
+	store.AddEvent(first)
+	store.AddEvent(testEvent("run-a", "agent-2", schema.TypeMessage, schema.StateRunning, base.Add(5*time.Minute)))
+
+	runs := store.ListRuns()
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(runs))
+	}
+	if runs[0].RunID != "run-a" || runs[1].RunID != "run-b" {
+		t.Errorf("expected runs ordered by start time, got %s, %s", runs[0].RunID, runs[1].RunID)
+	}
+
+	a := runs[0]
+	if a.AgentCount != 2 || a.EventCount != 2 {
+		t.Errorf("run-a: expected 2 agents/2 events, got %d/%d", a.AgentCount, a.EventCount)
+	}
+	if !a.StartTime.Equal(base) || !a.EndTime.Equal(base.Add(5*time.Minute)) {
+		t.Errorf("run-a: unexpected span %v..%v", a.StartTime, a.EndTime)
+	}
+	if a.Mode != schema.ModeTeam {
+		t.Errorf("run-a: expected mode team, got %s", a.Mode)
+	}
+
+	if store.GetRun("missing") != nil {
+		t.Error("unknown run should return nil")
+	}
+}
+
+func TestSelectRun_ScopesGetters(t *testing.T) {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	store.AddEvent(testEvent("run-a", "agent-1", schema.TypeMessage, schema.StateRunning, base))
+	store.AddEvent(testEvent("run-a", "agent-2", schema.TypeMessage, schema.StateRunning, base.Add(time.Second)))
+	store.AddEvent(testEvent("run-b", "agent-3", schema.TypeMessage, schema.StateRunning, base.Add(2*time.Second)))
+
+	if got := len(store.GetAllAgents()); got != 3 {
+		t.Fatalf("expected 3 agents across runs, got %d", got)
+	}
+
+	if err := store.SelectRun("run-a"); err != nil {
+		t.Fatalf("SelectRun: %v", err)
+	}
+	if got := len(store.GetAllAgents()); got != 2 {
+		t.Errorf("expected 2 agents in run-a, got %d", got)
+	}
+	if store.GetAgent("agent-3") != nil {
+		t.Error("agent-3 belongs to run-b and should be hidden")
+	}
+	if got := store.GetMetrics().EventCount; got != 2 {
+		t.Errorf("expected 2 events in run-a metrics, got %d", got)
+	}
+	if got := len(store.GetEvents(0)); got != 2 {
+		t.Errorf("expected 2 run-a events, got %d", got)
+	}
+	if got := store.GetRunID(); got != "run-a" {
+		t.Errorf("expected selected run id, got %s", got)
+	}
+
+	if err := store.SelectRun("run-x"); err == nil {
+		t.Error("selecting an unknown run should fail")
+	}
+	if store.SelectedRun() != "run-a" {
+		t.Error("failed selection should keep the previous run")
+	}
+
+	if err := store.SelectRun(""); err != nil {
+		t.Fatalf("clear selection: %v", err)
+	}
+	if got := store.GetMetrics().EventCount; got != 3 {
+		t.Errorf("expected 3 events after clearing selection, got %d", got)
+	}
+	if got := store.GetRunID(); got != "run-b" {
+		t.Errorf("expected latest run id, got %s", got)
+	}
+}
+
+func TestRuns_TransitionsValidatedPerRun(t *testing.T) {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	// The same agent ID finishes in one run and starts fresh in the next.
+	store.AddEvent(testEvent("run-a", "main", schema.TypeMessage, schema.StateRunning, base))
+	store.AddEvent(testEvent("run-a", "main", schema.TypeMessage, schema.StateDone, base.Add(time.Second)))
+	store.AddEvent(testEvent("run-b", "main", schema.TypeMessage, schema.StateRunning, base.Add(2*time.Second)))
+
+	if got := store.GetWarningCount(); got != 0 {
+		t.Errorf("expected no warnings across runs, got %d", got)
+	}
+}
@@ -0,0 +1,304 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// defaultSegmentSize is the size at which a SegmentLog starts a new segment.
+const defaultSegmentSize = 64 * 1024 * 1024 // 64MB
+
+// SegmentLog is an EventLog stored as a directory of append-only JSONL
+// segment files (00000001.jsonl, 00000002.jsonl, ...). Each segment's time
+// range is kept in memory so time-bounded scans skip whole files.
+type SegmentLog struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	segments []*segment
+	current  *os.File // open handle of the last segment
+}
+
+// segment describes one segment file.
+type segment struct {
+	path  string
+	size  int64
+	count int
+	first time.Time // earliest event Ts
+	last  time.Time // latest event Ts
+}
+
+// OpenSegmentLog opens or creates a segment log in dir. Existing segments
+// are scanned once to recover their time ranges. maxBytes <= 0 uses the
+// default segment size of 64MB.
+func OpenSegmentLog(dir string, maxBytes int64) (*SegmentLog, error) {
+	if maxBytes <= 0 {
+		maxBytes = defaultSegmentSize
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create log dir: %w", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("list segments: %w", err)
+	}
+	sort.Strings(paths)
+
+	// A crash mid-append can leave a torn line at the end of the last
+	// segment; cut it off so new appends start on a clean line.
+	if len(paths) > 0 {
+		if err := repairTail(paths[len(paths)-1]); err != nil {
+			return nil, err
+		}
+	}
+
+	l := &SegmentLog{dir: dir, maxBytes: maxBytes}
+	for _, path := range paths {
+		seg, err := loadSegment(path)
+		if err != nil {
+			return nil, err
+		}
+		l.segments = append(l.segments, seg)
+	}
+	return l, nil
+}
+
+// loadSegment recovers a segment's size, count and time range.
+func loadSegment(path string) (*segment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat segment: %w", err)
+	}
+	seg := &segment{path: path, size: info.Size()}
+	err = seg.scan(func(event schema.CanonicalEvent) bool {
+		seg.observe(event)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return seg, nil
+}
+
+// repairTail truncates a file after its last newline.
+func repairTail(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("open segment: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat segment: %w", err)
+	}
+
+	buf := make([]byte, 64*1024)
+	end := info.Size()
+	for end > 0 {
+		start := end - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+		chunk := buf[:end-start]
+		if _, err := f.ReadAt(chunk, start); err != nil {
+			return fmt.Errorf("read segment: %w", err)
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			end = start + int64(i) + 1
+			break
+		}
+		end = start
+	}
+
+	if end == info.Size() {
+		return nil
+	}
+	if err := f.Truncate(end); err != nil {
+		return fmt.Errorf("truncate torn segment: %w", err)
+	}
+	return nil
+}
+
+// Append writes an event to the last segment, rotating first if it is full.
+func (l *SegmentLog) Append(event schema.CanonicalEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	seg, err := l.writable(int64(len(line)))
+	if err != nil {
+		return err
+	}
+	if _, err := l.current.Write(line); err != nil {
+		return fmt.Errorf("write segment: %w", err)
+	}
+	seg.size += int64(len(line))
+	seg.observe(event)
+	return nil
+}
+
+// writable returns the segment the next n bytes should go to, opening or
+// rotating segment files as needed. The caller must hold l.mu.
+func (l *SegmentLog) writable(n int64) (*segment, error) {
+	var seg *segment
+	if len(l.segments) > 0 {
+		seg = l.segments[len(l.segments)-1]
+	}
+
+	if seg == nil || (seg.size > 0 && seg.size+n > l.maxBytes) {
+		if l.current != nil {
+			if err := l.current.Close(); err != nil {
+				return nil, fmt.Errorf("close segment: %w", err)
+			}
+			l.current = nil
+		}
+		seg = &segment{path: filepath.Join(l.dir, fmt.Sprintf("%08d.jsonl", len(l.segments)+1))}
+		l.segments = append(l.segments, seg)
+	}
+
+	if l.current == nil {
+		f, err := os.OpenFile(seg.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+		if err != nil {
+			return nil, fmt.Errorf("open segment: %w", err)
+		}
+		l.current = f
+	}
+	return seg, nil
+}
+
+// Scan calls fn for each event in [since, until), segment by segment.
+func (l *SegmentLog) Scan(since, until time.Time, fn func(schema.CanonicalEvent) bool) error {
+	l.mu.Lock()
+	segments := make([]segment, len(l.segments))
+	for i, seg := range l.segments {
+		segments[i] = *seg
+	}
+	l.mu.Unlock()
+
+	for _, seg := range segments {
+		if seg.count == 0 {
+			continue
+		}
+		if !since.IsZero() && seg.last.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !seg.first.Before(until) {
+			continue
+		}
+
+		stopped := false
+		err := seg.scan(func(event schema.CanonicalEvent) bool {
+			if !since.IsZero() && event.Ts.Before(since) {
+				return true
+			}
+			if !until.IsZero() && !event.Ts.Before(until) {
+				return true
+			}
+			if !fn(event) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if stopped {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Segments returns the number of segment files in the log.
+func (l *SegmentLog) Segments() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.segments)
+}
+
+// Close syncs and closes the open segment.
+func (l *SegmentLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.current == nil {
+		return nil
+	}
+	err := l.current.Sync()
+	if cerr := l.current.Close(); err == nil {
+		err = cerr
+	}
+	l.current = nil
+	return err
+}
+
+// observe extends the segment's count and time range with an event.
+func (seg *segment) observe(event schema.CanonicalEvent) {
+	if seg.count == 0 || event.Ts.Before(seg.first) {
+		seg.first = event.Ts
+	}
+	if seg.count == 0 || event.Ts.After(seg.last) {
+		seg.last = event.Ts
+	}
+	seg.count++
+}
+
+// scan decodes the segment's events in order. A torn final line, left by
+// a crash mid-write, is ignored; corrupt lines elsewhere are errors.
+func (seg *segment) scan(fn func(schema.CanonicalEvent) bool) error {
+	f, err := os.Open(seg.path)
+	if err != nil {
+		return fmt.Errorf("open segment: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	// Lines are read without a size limit: Append accepts events of any
+	// size, so a cap here would make the store unopenable
+	reader := bufio.NewReaderSize(f, 64*1024)
+
+	lineNum := 0
+	var pending error
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			lineNum++
+			if len(bytes.TrimSpace(line)) > 0 {
+				if pending != nil {
+					return pending
+				}
+
+				var event schema.CanonicalEvent
+				if err := json.Unmarshal(line, &event); err != nil {
+					pending = fmt.Errorf("%s line %d: %w", filepath.Base(seg.path), lineNum, err)
+				} else if !fn(event) {
+					return nil
+				}
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("scan segment: %w", readErr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+func collect(t *testing.T, l EventLog, since, until time.Time) []schema.CanonicalEvent {
+	t.Helper()
+	var events []schema.CanonicalEvent
+	if err := l.Scan(since, until, func(e schema.CanonicalEvent) bool {
+		events = append(events, e)
+		return true
+	}); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	return events
+}
+
+func TestSegmentLog_RotateAndScan(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	// Tiny segments force a rotation every couple of events.
+	l, err := OpenSegmentLog(dir, 400)
+	if err != nil {
+		t.Fatalf("OpenSegmentLog: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := l.Append(testEvent("run-1", "agent-1", schema.TypeMessage, schema.StateRunning, base.Add(time.Duration(i)*time.Minute))); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if l.Segments() < 3 {
+		t.Errorf("expected several segments, got %d", l.Segments())
+	}
+
+	if got := collect(t, l, time.Time{}, time.Time{}); len(got) != 10 {
+		t.Errorf("expected 10 events, got %d", len(got))
+	}
+	window := collect(t, l, base.Add(3*time.Minute), base.Add(6*time.Minute))
+	if len(window) != 3 {
+		t.Fatalf("expected 3 events in window, got %d", len(window))
+	}
+	if !window[0].Ts.Equal(base.Add(3 * time.Minute)) {
+		t.Errorf("window starts at %v, want +3m", window[0].Ts)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Reopen and keep appending.
+	l2, err := OpenSegmentLog(dir, 400)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer func() { _ = l2.Close() }()
+	if err := l2.Append(testEvent("run-1", "agent-2", schema.TypeMessage, schema.StateRunning, base.Add(10*time.Minute))); err != nil {
+		t.Fatalf("Append after reopen: %v", err)
+	}
+	if got := collect(t, l2, time.Time{}, time.Time{}); len(got) != 11 {
+		t.Errorf("expected 11 events after reopen, got %d", len(got))
+	}
+}
+
+func TestSegmentLog_RepairsTornTail(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	l, err := OpenSegmentLog(dir, 0)
+	if err != nil {
+		t.Fatalf("OpenSegmentLog: %v", err)
+	}
+	_ = l.Append(testEvent("run-1", "agent-1", schema.TypeMessage, schema.StateRunning, base))
+	_ = l.Close()
+
+	// Simulate a crash halfway through the next write.
+	f, err := os.OpenFile(filepath.Join(dir, "00000001.jsonl"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open segment: %v", err)
+	}
+	_, _ = f.WriteString(`{"ts":"2026-02-17T10:01:00Z","run_id":"ru`)
+	_ = f.Close()
+
+	l2, err := OpenSegmentLog(dir, 0)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer func() { _ = l2.Close() }()
+	_ = l2.Append(testEvent("run-1", "agent-2", schema.TypeMessage, schema.StateRunning, base.Add(2*time.Minute)))
+
+	got := collect(t, l2, time.Time{}, time.Time{})
+	if len(got) != 2 || got[1].AgentID != "agent-2" {
+		t.Errorf("expected torn line dropped and new event kept, got %+v", got)
+	}
+}
+
+func TestSegmentLog_ReopensWithLargeEvent(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	l, err := OpenSegmentLog(dir, 0)
+	if err != nil {
+		t.Fatalf("OpenSegmentLog: %v", err)
+	}
+	// A tool result bigger than any fixed line buffer.
+	big := testEvent("run-1", "agent-1", schema.TypeMessage, schema.StateRunning, base)
+	big.Payload = json.RawMessage(`{"output":"` + strings.Repeat("x", 5*1024*1024) + `"}`)
+	if err := l.Append(big); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	_ = l.Append(testEvent("run-1", "agent-2", schema.TypeMessage, schema.StateRunning, base.Add(time.Minute)))
+	_ = l.Close()
+
+	l2, err := OpenSegmentLog(dir, 0)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer func() { _ = l2.Close() }()
+
+	got := collect(t, l2, time.Time{}, time.Time{})
+	if len(got) != 2 || len(got[0].Payload) != len(big.Payload) || got[1].AgentID != "agent-2" {
+		t.Errorf("expected large event and its successor after reopen, got %d events", len(got))
+	}
+}
+
+func TestOpen_RestoresAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	s, err := Open(dir, 5)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !s.Persistent() {
+		t.Fatal("store should be persistent")
+	}
+	for i := 0; i < 20; i++ {
+		agent := "agent-a"
+		if i >= 15 {
+			agent = "agent-b"
+		}
+		s.AddEvent(testEvent("run-1", agent, schema.TypeMessage, schema.StateRunning, base.Add(time.Duration(i)*time.Minute)))
+	}
+	want := s.GetMetrics()
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	restored, err := Open(dir, 5)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer func() { _ = restored.Close() }()
+
+	if got := restored.GetMetrics(); got != want {
+		t.Errorf("metrics after restart = %+v, want %+v", got, want)
+	}
+	if got := len(restored.GetAllAgents()); got != 2 {
+		t.Errorf("expected 2 agents after restart, got %d", got)
+	}
+	if got := restored.EventCount(); got != 5 {
+		t.Errorf("ring should hold 5 events, got %d", got)
+	}
+
+	// agent-a only exists in events evicted from the ring.
+	if got := restored.Query(Filter{AgentIDs: []string{"agent-a"}}); len(got) != 0 {
+		t.Errorf("ring query should not see evicted events, got %d", len(got))
+	}
+	history, err := restored.History(Filter{AgentIDs: []string{"agent-a"}})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 15 {
+		t.Errorf("expected 15 agent-a events in history, got %d", len(history))
+	}
+
+	recent, err := restored.History(Filter{Since: base.Add(2 * time.Minute), Limit: 4})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(recent) != 4 || !recent[3].Ts.Equal(base.Add(19*time.Minute)) {
+		t.Errorf("expected the 4 most recent events, got %d", len(recent))
+	}
+}
+
+func TestHistory_WithoutLog(t *testing.T) {
+	s := NewStore(10)
+	s.AddEvent(testEvent("run-1", "agent-1", schema.TypeMessage, schema.StateRunning, time.Now()))
+
+	got, err := s.History(Filter{})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("expected 1 event, got %d", len(got))
+	}
+	if s.Persistent() {
+		t.Error("plain store should not be persistent")
+	}
+}
@@ -0,0 +1,230 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"sort"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/internal/codec"
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// snapshotVersion is bumped whenever the snapshot layout changes
+// incompatibly.
+const snapshotVersion = 1
+
+// snapshotFile is the serialized form of a Store.
+type snapshotFile struct {
+	Version   int                     `json:"version"`
+	Seq       uint64                  `json:"seq"`
+	Events    []schema.CanonicalEvent `json:"events"` // ring buffer, oldest first
+	All       stateSnapshot           `json:"all"`
+	Runs      []runSnapshot           `json:"runs"`
+	Selected  string                  `json:"selected,omitempty"`
+	RunID     string                  `json:"run_id,omitempty"`
+	Mode      schema.Mode             `json:"mode,omitempty"`
+	WarnCount int                     `json:"warn_count"`
+}
+
+// runSnapshot is the serialized form of a single run.
+type runSnapshot struct {
+	Info  RunInfo       `json:"info"`
+	State stateSnapshot `json:"state"`
+}
+
+// stateSnapshot is the serialized form of a runState.
+type stateSnapshot struct {
+	Agents       map[string]*AgentInfo    `json:"agents"`
+	Tasks        map[string]*TaskInfo     `json:"tasks"`
+	Metrics      Metrics                  `json:"metrics"`
+	Tools        map[string]*ToolStats    `json:"tools"`
+	OpenSpans    map[string]pendingCall   `json:"open_spans"`
+	ActiveTasks  map[string]string        `json:"active_tasks"`
+	AgentMetrics map[string]*Metrics      `json:"agent_metrics"`
+	RoleMetrics  map[schema.Role]*Metrics `json:"role_metrics"`
+	TaskMetrics  map[string]*Metrics      `json:"task_metrics"`
+	ToolMetrics  map[string]*Metrics      `json:"tool_metrics"`
+}
+
+// Snapshot writes the Store's complete state (retained events, agents,
+// tasks, runs and every aggregate) to w as JSON.
+func (s *Store) Snapshot(w io.Writer) error {
+	// Encode while holding the lock: the exported maps share pointers
+	// with the live state.
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snap := snapshotFile{
+		Version:   snapshotVersion,
+		Seq:       s.seq,
+		Events:    s.recent(0),
+		All:       s.all.export(),
+		Runs:      make([]runSnapshot, 0, len(s.runs)),
+		Selected:  s.selected,
+		RunID:     s.runID,
+		Mode:      s.mode,
+		WarnCount: s.warnCount,
+	}
+	for _, r := range s.runs {
+		snap.Runs = append(snap.Runs, runSnapshot{Info: r.info, State: r.state.export()})
+	}
+	sort.Slice(snap.Runs, func(i, j int) bool {
+		return snap.Runs[i].Info.RunID < snap.Runs[j].Info.RunID
+	})
+
+	if err := json.NewEncoder(w).Encode(snap); err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the Store's state with a snapshot written by Snapshot.
+// If the snapshot holds more events than the ring buffer, only the newest
+// are kept; aggregates are restored in full either way. Subscribers stay
+// registered but are not notified. Restoring into a persistent store is
+// refused since its event log would no longer match its state.
+func (s *Store) Restore(r io.Reader) error {
+	var snap snapshotFile
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.history != nil {
+		return errors.New("cannot restore a snapshot into a persistent store")
+	}
+
+	events := snap.Events
+	if len(events) > s.maxEvents {
+		events = events[len(events)-s.maxEvents:]
+	}
+	if uint64(len(events)) > snap.Seq {
+		return fmt.Errorf("corrupt snapshot: %d events but sequence %d", len(events), snap.Seq)
+	}
+
+	s.resetRing()
+	s.seq = snap.Seq - uint64(len(events))
+	// Slots are addressed by seq % maxEvents, so start writing at the
+	// slot the first kept event's sequence maps to
+	s.writeIdx = int(s.seq % uint64(s.maxEvents))
+	for _, event := range events {
+		s.push(event)
+	}
+
+	s.all = snap.All.restore()
+	s.runs = make(map[string]*run, len(snap.Runs))
+	for _, rs := range snap.Runs {
+		s.runs[rs.Info.RunID] = &run{info: rs.Info, state: rs.State.restore()}
+	}
+	s.selected = snap.Selected
+	if _, ok := s.runs[s.selected]; !ok {
+		s.selected = ""
+	}
+	s.runID = snap.RunID
+	s.mode = snap.Mode
+	s.warnCount = snap.WarnCount
+	return nil
+}
+
+// SaveSnapshot writes a snapshot to path, gzip-compressed for .gz paths.
+func (s *Store) SaveSnapshot(path string) error {
+	w, err := codec.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := s.Snapshot(w); err != nil {
+		_ = w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("close snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot restores a snapshot from path, written by SaveSnapshot.
+func (s *Store) LoadSnapshot(path string) error {
+	r, err := codec.Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return s.Restore(r)
+}
+
+// Reset discards all events and aggregates, returning the Store to the
+// state of a new one with the same capacity. Subscribers and settings are
+// kept; an attached EventLog is left untouched.
+func (s *Store) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.resetRing()
+	s.all = newRunState()
+	s.runs = make(map[string]*run)
+	s.selected = ""
+	s.runID = ""
+	s.mode = ""
+	s.warnCount = 0
+}
+
+// resetRing empties the ring buffer and its indexes.
+// The caller must hold s.mu.
+func (s *Store) resetRing() {
+	s.events = make([]schema.CanonicalEvent, s.maxEvents)
+	s.writeIdx = 0
+	s.count = 0
+	s.seq = 0
+	s.byAgent = make(map[string][]uint64)
+	s.byType = make(map[schema.EventType][]uint64)
+	s.byState = make(map[schema.AgentState][]uint64)
+	s.byTask = make(map[string][]uint64)
+	s.byRun = make(map[string][]uint64)
+}
+
+// export returns the serializable form of the aggregates.
+func (st *runState) export() stateSnapshot {
+	return stateSnapshot{
+		Agents:       st.agents,
+		Tasks:        st.tasks,
+		Metrics:      st.metrics,
+		Tools:        st.tools,
+		OpenSpans:    st.openSpans,
+		ActiveTasks:  st.activeTasks,
+		AgentMetrics: st.agentMetrics,
+		RoleMetrics:  st.roleMetrics,
+		TaskMetrics:  st.taskMetrics,
+		ToolMetrics:  st.toolMetrics,
+	}
+}
+
+// restore rebuilds aggregates from their serialized form.
+func (snap stateSnapshot) restore() *runState {
+	st := newRunState()
+	maps.Copy(st.agents, snap.Agents)
+	for _, agent := range st.agents {
+		if agent.TimeInState == nil {
+			agent.TimeInState = make(map[schema.AgentState]time.Duration)
+		}
+	}
+	st.relink()
+	maps.Copy(st.tasks, snap.Tasks)
+	st.metrics = snap.Metrics
+	maps.Copy(st.tools, snap.Tools)
+	maps.Copy(st.openSpans, snap.OpenSpans)
+	maps.Copy(st.activeTasks, snap.ActiveTasks)
+	maps.Copy(st.agentMetrics, snap.AgentMetrics)
+	maps.Copy(st.roleMetrics, snap.RoleMetrics)
+	maps.Copy(st.taskMetrics, snap.TaskMetrics)
+	maps.Copy(st.toolMetrics, snap.ToolMetrics)
+	return st
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+func snapshotFixture() *Store {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+	cost := 0.5
+
+	store.AddEvent(testEvent("run-a", "agent-1", schema.TypeMessage, schema.StateRunning, base))
+	store.AddEvent(testEvent("run-b", "agent-2", schema.TypeMessage, schema.StateRunning, base.Add(time.Second)))
+
+	call := testEvent("run-a", "agent-1", schema.TypeMessage, schema.StateRunning, base.Add(2*time.Second))
+	call.Type = schema.TypeToolCall
+	call.SpanID = "span-1"
+	call.Payload = []byte(`{"tool_name":"Read"}`)
+	call.Metrics = &schema.EventMetrics{CostUSD: &cost}
+	store.AddEvent(call)
+
+	store.AddEvent(testEvent("run-b", "agent-2", schema.TypeMessage, schema.StateDone, base.Add(3*time.Second)))
+	return store
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	src := snapshotFixture()
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	dst := NewStore(100)
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(dst.GetEvents(0), src.GetEvents(0)) {
+		t.Error("restored events differ")
+	}
+	if dst.GetMetrics() != src.GetMetrics() {
+		t.Errorf("metrics differ: %+v vs %+v", dst.GetMetrics(), src.GetMetrics())
+	}
+	if !reflect.DeepEqual(dst.ListRuns(), src.ListRuns()) {
+		t.Error("restored runs differ")
+	}
+	if dst.PendingToolCalls() != 1 {
+		t.Errorf("expected pending tool call to survive, got %d", dst.PendingToolCalls())
+	}
+	if got := dst.GetAgent("agent-2"); got == nil || got.State != schema.StateDone {
+		t.Errorf("expected agent-2 done, got %+v", got)
+	}
+	if got := dst.Query(Filter{AgentIDs: []string{"agent-1"}}); len(got) != 2 {
+		t.Errorf("expected indexes rebuilt with 2 agent-1 events, got %d", len(got))
+	}
+
+	// The restored store keeps aggregating from where the snapshot left off
+	result := testEvent("run-a", "agent-1", schema.TypeMessage, schema.StateDone, time.Date(2026, 2, 17, 10, 0, 5, 0, time.UTC))
+	result.Type = schema.TypeToolResult
+	result.SpanID = "span-1"
+	dst.AddEvent(result)
+	if stats := dst.GetToolStats("Read"); stats == nil || stats.Results != 1 {
+		t.Errorf("expected tool result paired after restore, got %+v", stats)
+	}
+	if dst.GetMetrics().EventCount != 5 {
+		t.Errorf("expected 5 events, got %d", dst.GetMetrics().EventCount)
+	}
+}
+
+func TestRestore_SmallerRing(t *testing.T) {
+	src := snapshotFixture()
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := NewStore(2)
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if dst.EventCount() != 2 {
+		t.Errorf("expected ring trimmed to 2 events, got %d", dst.EventCount())
+	}
+	if dst.GetMetrics().EventCount != 4 {
+		t.Errorf("aggregates should cover all 4 events, got %d", dst.GetMetrics().EventCount)
+	}
+	if got := dst.Query(Filter{AgentIDs: []string{"agent-1"}}); len(got) != 1 {
+		t.Errorf("expected 1 retained agent-1 event, got %d", len(got))
+	}
+}
+
+func TestRestore_WrappedRing(t *testing.T) {
+	src := NewStore(10)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 13; i++ {
+		src.AddEvent(testEvent("run-a", fmt.Sprintf("a%d", i), schema.TypeMessage, schema.StateRunning, base.Add(time.Duration(i)*time.Second)))
+	}
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dst := NewStore(20)
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if got := dst.Query(Filter{AgentIDs: []string{"a12"}}); len(got) != 1 || got[0].AgentID != "a12" {
+		t.Errorf("indexed query = %+v, want the a12 event", got)
+	}
+	all := dst.Query(Filter{})
+	if len(all) != 10 {
+		t.Fatalf("expected 10 retained events, got %d", len(all))
+	}
+	for i, event := range all {
+		if want := fmt.Sprintf("a%d", i+3); event.AgentID != want {
+			t.Errorf("event %d = %q, want %q", i, event.AgentID, want)
+		}
+	}
+
+	// New events land after the restored ones
+	dst.AddEvent(testEvent("run-a", "a13", schema.TypeMessage, schema.StateRunning, base.Add(13*time.Second)))
+	if got := dst.Query(Filter{AgentIDs: []string{"a13"}}); len(got) != 1 {
+		t.Errorf("expected a13 after restore, got %d", len(got))
+	}
+	if got := dst.GetEvents(2); len(got) != 2 || got[0].AgentID != "a12" || got[1].AgentID != "a13" {
+		t.Errorf("latest events = %+v, want a12, a13", got)
+	}
+}
+
+func TestSaveLoadSnapshot_Compressed(t *testing.T) {
+	src := snapshotFixture()
+	path := filepath.Join(t.TempDir(), "state.snap.gz")
+
+	if err := src.SaveSnapshot(path); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	dst := NewStore(100)
+	if err := dst.LoadSnapshot(path); err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	if dst.GetMetrics() != src.GetMetrics() {
+		t.Error("metrics differ after compressed round trip")
+	}
+}
+
+func TestRestore_Invalid(t *testing.T) {
+	store := NewStore(10)
+	if err := store.Restore(bytes.NewBufferString(`{"version":99}`)); err == nil {
+		t.Error("expected error for unsupported version")
+	}
+	if err := store.Restore(bytes.NewBufferString(`not json`)); err == nil {
+		t.Error("expected error for invalid snapshot")
+	}
+}
@@ -0,0 +1,340 @@
+package store
+
+import (
+	"log"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// runState holds the aggregates derived from events, either for a single
+// run or for every run combined.
+type runState struct {
+	agents  map[string]*AgentInfo
+	tasks   map[string]*TaskInfo
+	metrics Metrics
+
+	tools     map[string]*ToolStats  // tool name -> paired call stats
+	openSpans map[string]pendingCall // span ID -> unmatched tool_call
+
+	activeTasks map[string]string // agent ID -> task it is working on
+
+	children map[string][]string // agent ID -> spawned agents, in order seen
+
+	// metric breakdowns of the same events
+	agentMetrics map[string]*Metrics
+	roleMetrics  map[schema.Role]*Metrics
+	taskMetrics  map[string]*Metrics
+	toolMetrics  map[string]*Metrics
+}
+
+func newRunState() *runState {
+	return &runState{
+		agents:    make(map[string]*AgentInfo),
+		tasks:     make(map[string]*TaskInfo),
+		tools:     make(map[string]*ToolStats),
+		openSpans: make(map[string]pendingCall),
+
+		activeTasks: make(map[string]string),
+		children:    make(map[string][]string),
+
+		agentMetrics: make(map[string]*Metrics),
+		roleMetrics:  make(map[schema.Role]*Metrics),
+		taskMetrics:  make(map[string]*Metrics),
+		toolMetrics:  make(map[string]*Metrics),
+	}
+}
+
+// apply folds an event into the aggregates.
+// Returns the agent's previous state and whether the event's state is an
+// invalid transition from it.
+func (st *runState) apply(event schema.CanonicalEvent) (schema.AgentState, bool) {
+	// Update agent state
+	from, invalid := st.updateAgent(event)
+
+	// Handle task lifecycle
+	st.updateTask(event)
+
+	// Pair tool calls with results
+	tool := st.updateTools(event)
+
+	// Aggregate metrics
+	st.updateMetrics(event, tool)
+
+	return from, invalid
+}
+
+// updateAgent updates or creates agent state and its lifecycle statistics.
+// Returns the previous state and whether the new one is an invalid transition.
+func (st *runState) updateAgent(event schema.CanonicalEvent) (schema.AgentState, bool) {
+	agent, exists := st.agents[event.AgentID]
+	if !exists {
+		agent = &AgentInfo{
+			AgentID:     event.AgentID,
+			Role:        event.Role,
+			State:       event.State,
+			LastSeen:    event.Ts,
+			FirstSeen:   event.Ts,
+			StateSince:  event.Ts,
+			TimeInState: make(map[schema.AgentState]time.Duration),
+		}
+		st.agents[event.AgentID] = agent
+		st.linkParent(agent, event.ParentAgentID)
+		if event.Type == schema.TypeError || event.State == schema.StateError {
+			agent.ErrorCount++
+		}
+		if event.State.IsTerminal() {
+			agent.TerminalAt = event.Ts
+		}
+		return "", false
+	}
+
+	// Validate state transition
+	from := agent.State
+	invalid := !schema.IsValidTransition(agent.State, event.State)
+
+	if event.Type == schema.TypeError || (event.State == schema.StateError && from != schema.StateError) {
+		agent.ErrorCount++
+	}
+	if from == schema.StateError && event.State != schema.StateError && !event.State.IsTerminal() {
+		agent.Recoveries++
+	}
+
+	if event.State != from {
+		if elapsed := event.Ts.Sub(agent.StateSince); elapsed > 0 {
+			agent.TimeInState[from] += elapsed
+		}
+		agent.StateSince = event.Ts
+		if event.State.IsTerminal() {
+			agent.TerminalAt = event.Ts
+		} else {
+			agent.TerminalAt = time.Time{}
+		}
+	}
+
+	agent.State = event.State
+	agent.LastSeen = event.Ts
+	agent.Stalled = false
+	st.linkParent(agent, event.ParentAgentID)
+	if event.Role != "" {
+		agent.Role = event.Role
+	}
+	return from, invalid
+}
+
+// linkParent records parentID as the agent's spawner. The first parent
+// seen wins; links that would form a cycle are ignored.
+func (st *runState) linkParent(agent *AgentInfo, parentID string) {
+	if parentID == "" || agent.ParentAgentID != "" || parentID == agent.AgentID {
+		return
+	}
+	for id := parentID; id != ""; {
+		parent, ok := st.agents[id]
+		if !ok {
+			break
+		}
+		if parent.ParentAgentID == agent.AgentID {
+			log.Printf("[WARN] ignoring parent %s for agent %s: would form a cycle", parentID, agent.AgentID)
+			return
+		}
+		id = parent.ParentAgentID
+	}
+	agent.ParentAgentID = parentID
+	st.children[parentID] = append(st.children[parentID], agent.AgentID)
+}
+
+// markStalls flags running agents whose last event is older than
+// threshold. Returns the agents that became stalled.
+func (st *runState) markStalls(now time.Time, threshold time.Duration) []*AgentInfo {
+	var stalled []*AgentInfo
+	for _, agent := range st.agents {
+		if agent.Stalled || agent.State != schema.StateRunning {
+			continue
+		}
+		if now.Sub(agent.LastSeen) >= threshold {
+			agent.Stalled = true
+			stalled = append(stalled, agent)
+		}
+	}
+	return stalled
+}
+
+// updateTask tracks task lifecycle from TaskID, Type and State alone;
+// payloads only enrich the task with a title, result, progress or message.
+// Any event carrying a TaskID creates the task if it is not known yet.
+func (st *runState) updateTask(event schema.CanonicalEvent) {
+	if event.TaskID == "" {
+		return
+	}
+
+	task, exists := st.tasks[event.TaskID]
+	if !exists {
+		task = &TaskInfo{
+			TaskID:  event.TaskID,
+			AgentID: event.AgentID,
+			State:   TaskActive,
+			Created: event.Ts,
+		}
+		if event.ParentAgentID != "" {
+			task.ParentTaskID = st.activeTasks[event.ParentAgentID]
+		}
+		st.tasks[event.TaskID] = task
+	}
+	if event.Ts.After(task.Updated) {
+		task.Updated = event.Ts
+	}
+	task.Duration = task.Updated.Sub(task.Created)
+
+	switch event.Type {
+	case schema.TypeTaskSpawn:
+		var payload schema.TaskSpawnPayload
+		if err := parsePayload(event.Payload, &payload); err == nil && payload.Title != "" {
+			task.Title = payload.Title
+		}
+		// Spawning a task that already ended restarts it
+		if exists && task.State != TaskActive {
+			task.Retries++
+		}
+		task.State = TaskActive
+		task.AgentID = event.AgentID
+		st.activeTasks[event.AgentID] = event.TaskID
+
+	case schema.TypeTaskUpdate:
+		var payload schema.TaskUpdatePayload
+		if err := parsePayload(event.Payload, &payload); err == nil {
+			if payload.Progress > 0 {
+				task.Progress = payload.Progress
+			}
+			if payload.Message != "" {
+				task.LastMessage = payload.Message
+			}
+		}
+
+	case schema.TypeTaskDone:
+		task.State = TaskDone
+		if event.State == schema.StateError {
+			task.State = TaskFailed
+		}
+		var payload schema.TaskDonePayload
+		if err := parsePayload(event.Payload, &payload); err == nil {
+			switch payload.Result {
+			case "failure":
+				task.State = TaskFailed
+			case "cancelled":
+				task.State = TaskCancelled
+			case "success":
+				task.State = TaskDone
+			}
+			if payload.Summary != "" {
+				task.LastMessage = payload.Summary
+			}
+		}
+		if task.State == TaskDone {
+			task.Progress = 100
+		}
+		st.endTask(task)
+
+	case schema.TypeError:
+		task.State = TaskFailed
+		st.endTask(task)
+
+	default:
+		// The agent resuming work on a failed task counts as a retry
+		if task.State == TaskFailed && event.State == schema.StateRunning {
+			task.State = TaskActive
+			task.Retries++
+			st.activeTasks[task.AgentID] = task.TaskID
+		}
+	}
+}
+
+// endTask clears a task from its agent's active slot.
+func (st *runState) endTask(task *TaskInfo) {
+	if st.activeTasks[task.AgentID] == task.TaskID {
+		delete(st.activeTasks, task.AgentID)
+	}
+}
+
+// updateTools pairs tool_call and tool_result events by SpanID and
+// accumulates per-tool latency. Results without a matching call are
+// counted but contribute no latency. Returns the resolved tool name, or
+// "" for non-tool events.
+func (st *runState) updateTools(event schema.CanonicalEvent) string {
+	switch event.Type {
+	case schema.TypeToolCall:
+		var payload schema.ToolCallPayload
+		_ = parsePayload(event.Payload, &payload)
+		stats := st.toolStats(payload.ToolName)
+		stats.Calls++
+		if event.SpanID != "" {
+			st.openSpans[event.SpanID] = pendingCall{ToolName: stats.ToolName, Ts: event.Ts}
+		}
+		return stats.ToolName
+
+	case schema.TypeToolResult:
+		var payload schema.ToolResultPayload
+		parsed := len(event.Payload) > 0 && parsePayload(event.Payload, &payload) == nil
+		name := payload.ToolName
+		call, paired := st.openSpans[event.SpanID]
+		if paired {
+			delete(st.openSpans, event.SpanID)
+			if name == "" {
+				name = call.ToolName
+			}
+		}
+		stats := st.toolStats(name)
+		stats.Results++
+		if (parsed && !payload.Success) || event.State == schema.StateError {
+			stats.Failures++
+		}
+		if paired {
+			latency := float64(event.Ts.Sub(call.Ts)) / float64(time.Millisecond)
+			if latency < 0 {
+				latency = 0
+			}
+			stats.TotalLatency += latency
+			if latency > stats.MaxLatency {
+				stats.MaxLatency = latency
+			}
+		}
+		return stats.ToolName
+	}
+	return ""
+}
+
+// toolStats returns the stats entry for a tool, creating it if needed.
+func (st *runState) toolStats(name string) *ToolStats {
+	if name == "" {
+		name = "unknown"
+	}
+	stats, ok := st.tools[name]
+	if !ok {
+		stats = &ToolStats{ToolName: name}
+		st.tools[name] = stats
+	}
+	return stats
+}
+
+// updateMetrics aggregates event metrics globally and per agent, role,
+// task and tool. tool is the resolved tool name for tool events.
+func (st *runState) updateMetrics(event schema.CanonicalEvent, tool string) {
+	st.metrics.add(event)
+	bucket(st.agentMetrics, event.AgentID).add(event)
+	bucket(st.roleMetrics, event.Role).add(event)
+	if event.TaskID != "" {
+		bucket(st.taskMetrics, event.TaskID).add(event)
+	}
+	if tool != "" {
+		bucket(st.toolMetrics, tool).add(event)
+	}
+}
+
+// bucket returns the Metrics entry for key, creating it if needed.
+func bucket[K comparable](m map[K]*Metrics, key K) *Metrics {
+	metrics, ok := m[key]
+	if !ok {
+		metrics = &Metrics{}
+		m[key] = metrics
+	}
+	return metrics
+}
@@ -2,6 +2,8 @@ package store
 
 import (
 	"log"
+	"maps"
+	"sort"
 	"sync"
 	"time"
 
@@ -9,39 +11,128 @@ import (
 )
 
 // Store holds all runtime state for the TUI.
+// Aggregates are kept both per run and across all runs; see SelectRun.
 // Thread-safe via RWMutex.
 type Store struct {
 	mu        sync.RWMutex
 	events    []schema.CanonicalEvent // ring buffer
 	maxEvents int
-	writeIdx  int // next write position
-	count     int // actual stored count
-
-	agents  map[string]*AgentInfo
-	tasks   map[string]*TaskInfo
-	metrics Metrics
-
-	runID     string
+	writeIdx  int    // next write position
+	count     int    // actual stored count
+	seq       uint64 // sequence number of the next event written
+
+	// secondary indexes: key -> ascending sequence numbers of retained events
+	byAgent map[string][]uint64
+	byType  map[schema.EventType][]uint64
+	byState map[schema.AgentState][]uint64
+	byTask  map[string][]uint64
+	byRun   map[string][]uint64
+
+	all      *runState       // aggregates across every run
+	runs     map[string]*run // per-run aggregates keyed by run ID
+	selected string          // run ID getters are scoped to; "" = all runs
+
+	runID     string // run of the most recent event
 	mode      schema.Mode
 	warnCount int // invalid transition warnings
+
+	stallThreshold time.Duration // see DetectStalls
+
+	history EventLog // optional durable full history; nil = memory only
+
+	subs      []*subscriber // change subscribers; see Subscribe
+	nextSubID int
+	dropped   int // changes discarded for full subscriber buffers
 }
 
-// AgentInfo tracks the current state of an agent.
+// AgentInfo tracks the current state of an agent and its lifecycle.
 type AgentInfo struct {
 	AgentID  string
 	Role     schema.Role
 	State    schema.AgentState
 	LastSeen time.Time
+
+	ParentAgentID string // agent that spawned this one; see GetChildren
+
+	FirstSeen   time.Time
+	StateSince  time.Time                           // when State was entered
+	TimeInState map[schema.AgentState]time.Duration // completed time per state
+	TerminalAt  time.Time                           // zero unless in a terminal state
+	ErrorCount  int                                 // error events and entries into StateError
+	Recoveries  int                                 // exits from StateError to a live state
+	Stalled     bool                                // running with no events past the stall threshold
+}
+
+// clone returns a copy that shares no maps with the original.
+func (a *AgentInfo) clone() *AgentInfo {
+	copied := *a
+	copied.TimeInState = maps.Clone(a.TimeInState)
+	return &copied
+}
+
+// cloneAgents clones each agent, so callers never share an AgentInfo the
+// Store keeps updating in place.
+func cloneAgents(agents []*AgentInfo) []*AgentInfo {
+	for i, agent := range agents {
+		agents[i] = agent.clone()
+	}
+	return agents
+}
+
+// TimeIn returns the total time spent in state, including the current
+// stint if the agent is still in it at now.
+func (a AgentInfo) TimeIn(state schema.AgentState, now time.Time) time.Duration {
+	d := a.TimeInState[state]
+	if a.State == state && now.After(a.StateSince) {
+		d += now.Sub(a.StateSince)
+	}
+	return d
+}
+
+// Uptime returns how long the agent has been alive: from FirstSeen until
+// it reached a terminal state, or until now.
+func (a AgentInfo) Uptime(now time.Time) time.Duration {
+	end := now
+	if !a.TerminalAt.IsZero() {
+		end = a.TerminalAt
+	}
+	if end.Before(a.FirstSeen) {
+		return 0
+	}
+	return end.Sub(a.FirstSeen)
 }
 
+// DefaultStallThreshold is how long a running agent may go without events
+// before DetectStalls flags it.
+const DefaultStallThreshold = 2 * time.Minute
+
+// Task states tracked in TaskInfo.State.
+const (
+	TaskActive    = "active"
+	TaskDone      = "done"
+	TaskFailed    = "failed"
+	TaskCancelled = "cancelled"
+)
+
 // TaskInfo tracks task lifecycle.
 type TaskInfo struct {
-	TaskID   string
-	AgentID  string
-	State    string // "active" | "done" | "failed" | "cancelled"
-	Title    string
-	Created  time.Time
-	Updated  time.Time
+	TaskID       string
+	AgentID      string
+	ParentTaskID string // task the spawning agent was working on
+	State        string // "active" | "done" | "failed" | "cancelled"
+	Title        string
+	Progress     int    // 0-100, from task_update payloads
+	LastMessage  string // latest task_update message or task_done summary
+	Retries      int    // restarts after the task failed or ended
+	Created      time.Time
+	Updated      time.Time
+	Duration     time.Duration // Created to the latest event for the task
+}
+
+// clone returns a copy the Store's later updates do not touch.
+func (t *TaskInfo) clone() *TaskInfo {
+	copied := *t
+	return &copied
 }
 
 // Metrics aggregates performance and cost data.
@@ -54,6 +145,55 @@ type Metrics struct {
 	TotalCostUSD   float64
 }
 
+// add folds a single event into the totals.
+func (m *Metrics) add(event schema.CanonicalEvent) {
+	m.EventCount++
+
+	if event.Type == schema.TypeError {
+		m.ErrorCount++
+	}
+
+	if event.Metrics != nil {
+		if event.Metrics.LatencyMs != nil {
+			m.TotalLatency += *event.Metrics.LatencyMs
+		}
+		if event.Metrics.TokensIn != nil {
+			m.TotalTokensIn += *event.Metrics.TokensIn
+		}
+		if event.Metrics.TokensOut != nil {
+			m.TotalTokensOut += *event.Metrics.TokensOut
+		}
+		if event.Metrics.CostUSD != nil {
+			m.TotalCostUSD += *event.Metrics.CostUSD
+		}
+	}
+}
+
+// ToolStats aggregates tool_call/tool_result pairs for a single tool.
+// Latency is measured between a call and the result sharing its SpanID.
+type ToolStats struct {
+	ToolName     string
+	Calls        int
+	Results      int
+	Failures     int
+	TotalLatency float64 // sum of paired latency_ms
+	MaxLatency   float64
+}
+
+// AvgLatency returns the mean paired latency in milliseconds.
+func (t ToolStats) AvgLatency() float64 {
+	if t.Results == 0 {
+		return 0
+	}
+	return t.TotalLatency / float64(t.Results)
+}
+
+// pendingCall is a tool_call waiting for its tool_result.
+type pendingCall struct {
+	ToolName string
+	Ts       time.Time
+}
+
 // NewStore creates a new Store with the specified ring buffer size.
 // Default maxEvents is 10,000.
 func NewStore(maxEvents int) *Store {
@@ -63,17 +203,38 @@ func NewStore(maxEvents int) *Store {
 	return &Store{
 		events:    make([]schema.CanonicalEvent, maxEvents),
 		maxEvents: maxEvents,
-		agents:    make(map[string]*AgentInfo),
-		tasks:     make(map[string]*TaskInfo),
+		all:       newRunState(),
+		runs:      make(map[string]*run),
+		byAgent:   make(map[string][]uint64),
+		byType:    make(map[schema.EventType][]uint64),
+		byState:   make(map[schema.AgentState][]uint64),
+		byTask:    make(map[string][]uint64),
+		byRun:     make(map[string][]uint64),
+
+		stallThreshold: DefaultStallThreshold,
 	}
 }
 
 // AddEvent stores an event in the ring buffer and updates state.
 // Invalid state transitions are logged as warnings but still accepted.
+// When an EventLog is attached the event is also persisted; write
+// failures are logged and do not drop the event from memory.
 func (s *Store) AddEvent(event schema.CanonicalEvent) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.history != nil {
+		if err := s.history.Append(event); err != nil {
+			log.Printf("[WARN] persist event for agent %s: %v", event.AgentID, err)
+		}
+	}
+
+	s.add(event)
+}
+
+// add applies an event to the ring buffer, indexes and aggregates.
+// The caller must hold s.mu.
+func (s *Store) add(event schema.CanonicalEvent) {
 	// Update run metadata
 	if event.RunID != "" {
 		s.runID = event.RunID
@@ -82,190 +243,155 @@ func (s *Store) AddEvent(event schema.CanonicalEvent) {
 		s.mode = event.Mode
 	}
 
-	// Store in ring buffer
-	s.events[s.writeIdx] = event
-	s.writeIdx = (s.writeIdx + 1) % s.maxEvents
-	if s.count < s.maxEvents {
-		s.count++
-	}
-
-	// Update agent state
-	s.updateAgent(event)
+	s.push(event)
 
-	// Handle task lifecycle
-	s.updateTask(event)
+	// Update aggregates for all runs and for the event's own run.
+	// Transitions are validated per run so an agent ID reused by a
+	// later run does not look like an invalid transition.
+	_, known := s.runs[event.RunID]
+	from, invalid := s.all.apply(event)
+	if r := s.trackRun(event); r != nil {
+		from, invalid = r.state.apply(event)
+	}
+	if invalid {
+		s.warnCount++
+		log.Printf("[WARN] invalid state transition for agent %s: %s -> %s",
+			event.AgentID, from, event.State)
+	}
 
-	// Aggregate metrics
-	s.updateMetrics(event)
+	s.publishChanges(event, event.RunID != "" && !known)
 }
 
-// updateAgent updates or creates agent state.
-// Validates state transitions and logs warnings for invalid transitions.
-func (s *Store) updateAgent(event schema.CanonicalEvent) {
-	agent, exists := s.agents[event.AgentID]
-
-	// Validate state transition
-	if exists {
-		if !schema.IsValidTransition(agent.State, event.State) {
-			s.warnCount++
-			log.Printf("[WARN] invalid state transition for agent %s: %s -> %s",
-				event.AgentID, agent.State, event.State)
-		}
+// push stores an event in the ring buffer and indexes it, dropping the
+// oldest entry from the indexes before its slot is overwritten.
+// The caller must hold s.mu.
+func (s *Store) push(event schema.CanonicalEvent) {
+	if s.count == s.maxEvents {
+		s.unindex(s.seq-uint64(s.maxEvents), s.events[s.writeIdx])
 	}
-
-	// Update or create
-	if exists {
-		agent.State = event.State
-		agent.LastSeen = event.Ts
-		if event.Role != "" {
-			agent.Role = event.Role
-		}
-	} else {
-		s.agents[event.AgentID] = &AgentInfo{
-			AgentID:  event.AgentID,
-			Role:     event.Role,
-			State:    event.State,
-			LastSeen: event.Ts,
-		}
+	s.events[s.writeIdx] = event
+	s.writeIdx = (s.writeIdx + 1) % s.maxEvents
+	if s.count < s.maxEvents {
+		s.count++
 	}
+	s.index(s.seq, event)
+	s.seq++
 }
 
-// updateTask handles task lifecycle events.
-func (s *Store) updateTask(event schema.CanonicalEvent) {
-	if event.TaskID == "" {
-		return
-	}
-
-	switch event.Type {
-	case schema.TypeTaskSpawn:
-		var payload schema.TaskSpawnPayload
-		if err := parsePayload(event.Payload, &payload); err == nil {
-			s.tasks[event.TaskID] = &TaskInfo{
-				TaskID:  event.TaskID,
-				AgentID: event.AgentID,
-				State:   "active",
-				Title:   payload.Title,
-				Created: event.Ts,
-				Updated: event.Ts,
-			}
-		}
-
-	case schema.TypeTaskDone:
-		if task, ok := s.tasks[event.TaskID]; ok {
-			var payload schema.TaskDonePayload
-			if err := parsePayload(event.Payload, &payload); err == nil {
-				switch payload.Result {
-				case "success":
-					task.State = "done"
-				case "failure":
-					task.State = "failed"
-				case "cancelled":
-					task.State = "cancelled"
-				default:
-					task.State = "done"
-				}
-				task.Updated = event.Ts
-			}
-		}
-
-	case schema.TypeTaskUpdate:
-		if task, ok := s.tasks[event.TaskID]; ok {
-			task.Updated = event.Ts
-		}
-	}
+// SetStallThreshold sets how long a running agent may go without events
+// before it is considered stalled. Zero or less disables stall detection.
+func (s *Store) SetStallThreshold(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stallThreshold = d
 }
 
-// updateMetrics aggregates event metrics.
-func (s *Store) updateMetrics(event schema.CanonicalEvent) {
-	s.metrics.EventCount++
+// DetectStalls flags running agents that have not emitted an event within
+// the stall threshold of now, in every run. The flag clears with the
+// agent's next event. Returns the IDs of agents newly flagged in the
+// current view, sorted, and publishes a ChangeAgent for each.
+func (s *Store) DetectStalls(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if event.Type == schema.TypeError {
-		s.metrics.ErrorCount++
+	if s.stallThreshold <= 0 {
+		return nil
 	}
 
-	if event.Metrics != nil {
-		if event.Metrics.LatencyMs != nil {
-			s.metrics.TotalLatency += *event.Metrics.LatencyMs
-		}
-		if event.Metrics.TokensIn != nil {
-			s.metrics.TotalTokensIn += *event.Metrics.TokensIn
-		}
-		if event.Metrics.TokensOut != nil {
-			s.metrics.TotalTokensOut += *event.Metrics.TokensOut
-		}
-		if event.Metrics.CostUSD != nil {
-			s.metrics.TotalCostUSD += *event.Metrics.CostUSD
+	stalled := s.all.markStalls(now, s.stallThreshold)
+	for _, r := range s.runs {
+		runStalled := r.state.markStalls(now, s.stallThreshold)
+		if r.info.RunID == s.selected {
+			stalled = runStalled
 		}
 	}
+
+	ids := make([]string, 0, len(stalled))
+	for _, agent := range stalled {
+		ids = append(ids, agent.AgentID)
+		s.publish(Change{Kind: ChangeAgent, Agent: agent.clone()})
+	}
+	sort.Strings(ids)
+	return ids
 }
 
 // GetEvents returns the most recent events, up to limit.
 // Returns events in chronological order (oldest first).
+// When a run is selected only that run's events are returned.
 func (s *Store) GetEvents(limit int) []schema.CanonicalEvent {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	if s.selected != "" {
+		return s.query(Filter{Limit: limit})
+	}
+	return s.recent(limit)
+}
+
+// recent returns up to limit of the newest events in the ring buffer,
+// oldest first. The caller must hold s.mu.
+func (s *Store) recent(limit int) []schema.CanonicalEvent {
 	if limit <= 0 || limit > s.count {
 		limit = s.count
 	}
 
 	result := make([]schema.CanonicalEvent, 0, limit)
 
-	if s.count < s.maxEvents {
-		// Buffer not full yet - read from start
-		start := s.count - limit
-		if start < 0 {
-			start = 0
-		}
-		for i := start; i < s.count; i++ {
-			result = append(result, s.events[i])
-		}
-	} else {
-		// Buffer is full - read from oldest
-		start := (s.writeIdx - limit + s.maxEvents) % s.maxEvents
-		for i := 0; i < limit; i++ {
-			idx := (start + i) % s.maxEvents
-			result = append(result, s.events[idx])
-		}
+	// The newest event sits just before writeIdx, which after a Restore
+	// need not be count even when the buffer is not full
+	start := (s.writeIdx - limit + s.maxEvents) % s.maxEvents
+	for i := 0; i < limit; i++ {
+		idx := (start + i) % s.maxEvents
+		result = append(result, s.events[idx])
 	}
 
 	return result
 }
 
-// GetAgent returns info for a specific agent.
+// GetAgent returns a copy of the info for a specific agent, or nil.
 func (s *Store) GetAgent(agentID string) *AgentInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.agents[agentID]
+	agent, ok := s.view().agents[agentID]
+	if !ok {
+		return nil
+	}
+	return agent.clone()
 }
 
-// GetAllAgents returns all agent info.
+// GetAllAgents returns a copy of all agent info.
 func (s *Store) GetAllAgents() []*AgentInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	result := make([]*AgentInfo, 0, len(s.agents))
-	for _, agent := range s.agents {
-		result = append(result, agent)
+	agents := s.view().agents
+	result := make([]*AgentInfo, 0, len(agents))
+	for _, agent := range agents {
+		result = append(result, agent.clone())
 	}
 	return result
 }
 
-// GetTask returns info for a specific task.
+// GetTask returns a copy of the info for a specific task, or nil.
 func (s *Store) GetTask(taskID string) *TaskInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.tasks[taskID]
+	task, ok := s.view().tasks[taskID]
+	if !ok {
+		return nil
+	}
+	return task.clone()
 }
 
-// GetAllTasks returns all task info.
+// GetAllTasks returns a copy of all task info.
 func (s *Store) GetAllTasks() []*TaskInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	result := make([]*TaskInfo, 0, len(s.tasks))
-	for _, task := range s.tasks {
-		result = append(result, task)
+	tasks := s.view().tasks
+	result := make([]*TaskInfo, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, task.clone())
 	}
 	return result
 }
@@ -274,20 +400,107 @@ func (s *Store) GetAllTasks() []*TaskInfo {
 func (s *Store) GetMetrics() Metrics {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.metrics
+	return s.view().metrics
+}
+
+// GetAgentMetrics returns metrics for events emitted by a single agent.
+func (s *Store) GetAgentMetrics(agentID string) Metrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if m, ok := s.view().agentMetrics[agentID]; ok {
+		return *m
+	}
+	return Metrics{}
 }
 
-// GetMode returns the current execution mode.
+// GetTaskMetrics returns metrics for events tagged with a single task.
+func (s *Store) GetTaskMetrics(taskID string) Metrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if m, ok := s.view().taskMetrics[taskID]; ok {
+		return *m
+	}
+	return Metrics{}
+}
+
+// GetRoleMetrics returns metrics broken down by agent role.
+func (s *Store) GetRoleMetrics() map[schema.Role]Metrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyMetrics(s.view().roleMetrics)
+}
+
+// GetToolMetrics returns metrics of tool_call/tool_result events broken
+// down by tool name.
+func (s *Store) GetToolMetrics() map[string]Metrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyMetrics(s.view().toolMetrics)
+}
+
+// copyMetrics returns a value copy of a metrics breakdown.
+func copyMetrics[K comparable](src map[K]*Metrics) map[K]Metrics {
+	result := make(map[K]Metrics, len(src))
+	for k, m := range src {
+		result[k] = *m
+	}
+	return result
+}
+
+// GetToolStats returns paired call statistics for a single tool, or nil.
+func (s *Store) GetToolStats(toolName string) *ToolStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stats, ok := s.view().tools[toolName]
+	if !ok {
+		return nil
+	}
+	copied := *stats
+	return &copied
+}
+
+// GetAllToolStats returns statistics for every tool, sorted by name.
+func (s *Store) GetAllToolStats() []ToolStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tools := s.view().tools
+	result := make([]ToolStats, 0, len(tools))
+	for _, stats := range tools {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ToolName < result[j].ToolName
+	})
+	return result
+}
+
+// PendingToolCalls returns the number of tool calls still awaiting a result.
+func (s *Store) PendingToolCalls() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.view().openSpans)
+}
+
+// GetMode returns the current execution mode: the selected run's mode,
+// or the mode of the most recent event when no run is selected.
 func (s *Store) GetMode() schema.Mode {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	if r, ok := s.runs[s.selected]; ok {
+		return r.info.Mode
+	}
 	return s.mode
 }
 
-// GetRunID returns the current run ID.
+// GetRunID returns the selected run ID, or the run of the most recent
+// event when no run is selected.
 func (s *Store) GetRunID() string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	if s.selected != "" {
+		return s.selected
+	}
 	return s.runID
 }
 
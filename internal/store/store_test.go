@@ -9,6 +9,19 @@ import (
 	"github.com/chamdom/omc-agent-tui/pkg/schema"
 )
 
+// testEvent returns an executor event; tests set any other field they need.
+func testEvent(runID, agentID string, typ schema.EventType, state schema.AgentState, ts time.Time) schema.CanonicalEvent {
+	return schema.CanonicalEvent{
+		Ts:       ts,
+		RunID:    runID,
+		Provider: schema.ProviderClaude,
+		AgentID:  agentID,
+		Role:     schema.RoleExecutor,
+		State:    state,
+		Type:     typ,
+	}
+}
+
 func TestNewStore(t *testing.T) {
 	store := NewStore(100)
 	if store.maxEvents != 100 {
@@ -541,3 +554,367 @@ func TestGetEventsLimit(t *testing.T) {
 		t.Errorf("expected 10 events, got %d", len(events))
 	}
 }
+
+func TestToolCallPairing(t *testing.T) {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	toolEvent := func(typ schema.EventType, span string, offset time.Duration, payload any) schema.CanonicalEvent {
+		data, _ := json.Marshal(payload)
+		return schema.CanonicalEvent{
+			Ts:       base.Add(offset),
+			RunID:    "run-1",
+			Provider: schema.ProviderClaude,
+			AgentID:  "agent-1",
+			Role:     schema.RoleExecutor,
+			State:    schema.StateRunning,
+			Type:     typ,
+			SpanID:   span,
+			Payload:  data,
+		}
+	}
+
+	// Two parallel Edit calls whose results arrive out of order.
+	store.AddEvent(toolEvent(schema.TypeToolCall, "s1", 0, schema.ToolCallPayload{ToolName: "Edit"}))
+	store.AddEvent(toolEvent(schema.TypeToolCall, "s2", 100*time.Millisecond, schema.ToolCallPayload{ToolName: "Edit"}))
+	store.AddEvent(toolEvent(schema.TypeToolCall, "s3", 200*time.Millisecond, schema.ToolCallPayload{ToolName: "Bash"}))
+
+	if got := store.PendingToolCalls(); got != 3 {
+		t.Errorf("expected 3 pending calls, got %d", got)
+	}
+
+	store.AddEvent(toolEvent(schema.TypeToolResult, "s2", 400*time.Millisecond, schema.ToolResultPayload{ToolName: "Edit", Success: true}))
+	store.AddEvent(toolEvent(schema.TypeToolResult, "s1", 1000*time.Millisecond, schema.ToolResultPayload{Success: false}))
+
+	edit := store.GetToolStats("Edit")
+	if edit == nil {
+		t.Fatal("Edit stats should exist")
+	}
+	if edit.Calls != 2 || edit.Results != 2 {
+		t.Errorf("expected 2 calls/2 results, got %d/%d", edit.Calls, edit.Results)
+	}
+	if edit.Failures != 1 {
+		t.Errorf("expected 1 failure, got %d", edit.Failures)
+	}
+	if edit.TotalLatency != 1300 {
+		t.Errorf("expected TotalLatency=1300, got %f", edit.TotalLatency)
+	}
+	if edit.MaxLatency != 1000 {
+		t.Errorf("expected MaxLatency=1000, got %f", edit.MaxLatency)
+	}
+	if edit.AvgLatency() != 650 {
+		t.Errorf("expected AvgLatency=650, got %f", edit.AvgLatency())
+	}
+
+	if got := store.PendingToolCalls(); got != 1 {
+		t.Errorf("expected 1 pending call, got %d", got)
+	}
+
+	all := store.GetAllToolStats()
+	if len(all) != 2 || all[0].ToolName != "Bash" || all[1].ToolName != "Edit" {
+		t.Errorf("expected [Bash Edit], got %+v", all)
+	}
+}
+
+func TestMetricsBreakdown(t *testing.T) {
+	store := NewStore(100)
+
+	withMetrics := func(agentID string, role schema.Role, typ schema.EventType, taskID string, cost float64, tokens int, payload any) schema.CanonicalEvent {
+		var raw json.RawMessage
+		if payload != nil {
+			raw, _ = json.Marshal(payload)
+		}
+		return schema.CanonicalEvent{
+			Ts:       time.Now(),
+			RunID:    "run-1",
+			Provider: schema.ProviderClaude,
+			AgentID:  agentID,
+			Role:     role,
+			State:    schema.StateRunning,
+			Type:     typ,
+			TaskID:   taskID,
+			Payload:  raw,
+			Metrics:  &schema.EventMetrics{CostUSD: &cost, TokensIn: &tokens},
+		}
+	}
+
+	store.AddEvent(withMetrics("exec-1", schema.RoleExecutor, schema.TypeToolCall, "task-1", 0.01, 10, schema.ToolCallPayload{ToolName: "Edit"}))
+	store.AddEvent(withMetrics("exec-1", schema.RoleExecutor, schema.TypeToolResult, "task-1", 0.02, 20, schema.ToolResultPayload{ToolName: "Edit", Success: true}))
+	store.AddEvent(withMetrics("exec-2", schema.RoleExecutor, schema.TypeError, "task-2", 0.04, 40, nil))
+	store.AddEvent(withMetrics("plan-1", schema.RolePlanner, schema.TypeMessage, "", 0.08, 80, nil))
+
+	if m := store.GetAgentMetrics("exec-1"); m.EventCount != 2 || m.TotalTokensIn != 30 {
+		t.Errorf("exec-1: expected 2 events/30 tokens, got %d/%d", m.EventCount, m.TotalTokensIn)
+	}
+	if m := store.GetAgentMetrics("nobody"); m.EventCount != 0 {
+		t.Errorf("unknown agent should have zero metrics, got %+v", m)
+	}
+	if m := store.GetTaskMetrics("task-2"); m.ErrorCount != 1 || m.TotalTokensIn != 40 {
+		t.Errorf("task-2: expected 1 error/40 tokens, got %d/%d", m.ErrorCount, m.TotalTokensIn)
+	}
+
+	roles := store.GetRoleMetrics()
+	if m := roles[schema.RoleExecutor]; m.EventCount != 3 || m.TotalTokensIn != 70 {
+		t.Errorf("executor: expected 3 events/70 tokens, got %d/%d", m.EventCount, m.TotalTokensIn)
+	}
+	if m := roles[schema.RolePlanner]; m.TotalTokensIn != 80 {
+		t.Errorf("planner: expected 80 tokens, got %d", m.TotalTokensIn)
+	}
+
+	tools := store.GetToolMetrics()
+	if len(tools) != 1 {
+		t.Fatalf("expected 1 tool, got %d", len(tools))
+	}
+	if m := tools["Edit"]; m.EventCount != 2 || m.TotalTokensIn != 30 {
+		t.Errorf("Edit: expected 2 events/30 tokens, got %d/%d", m.EventCount, m.TotalTokensIn)
+	}
+}
+
+func TestTaskLifecycle_WithoutPayload(t *testing.T) {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	ev := func(agentID string, typ schema.EventType, state schema.AgentState, taskID string, offset time.Duration) schema.CanonicalEvent {
+		return schema.CanonicalEvent{
+			Ts: base.Add(offset), RunID: "run-1", Provider: schema.ProviderClaude,
+			AgentID: agentID, Role: schema.RoleExecutor, State: state,
+			Type: typ, TaskID: taskID,
+		}
+	}
+
+	store.AddEvent(ev("agent-1", schema.TypeTaskSpawn, schema.StateRunning, "task-1", 0))
+	task := store.GetTask("task-1")
+	if task == nil || task.State != TaskActive {
+		t.Fatalf("expected active task without payload, got %+v", task)
+	}
+
+	store.AddEvent(ev("agent-1", schema.TypeTaskDone, schema.StateDone, "task-1", 3*time.Second))
+	task = store.GetTask("task-1")
+	if task.State != TaskDone {
+		t.Errorf("expected done, got %s", task.State)
+	}
+	if task.Duration != 3*time.Second {
+		t.Errorf("expected duration 3s, got %s", task.Duration)
+	}
+
+	// A done event in the error state marks the task failed
+	store.AddEvent(ev("agent-2", schema.TypeTaskSpawn, schema.StateRunning, "task-2", 0))
+	store.AddEvent(ev("agent-2", schema.TypeTaskDone, schema.StateError, "task-2", time.Second))
+	if got := store.GetTask("task-2").State; got != TaskFailed {
+		t.Errorf("expected failed, got %s", got)
+	}
+}
+
+func TestTaskEnrichment(t *testing.T) {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	parent := schema.CanonicalEvent{
+		Ts: base, RunID: "run-1", Provider: schema.ProviderClaude,
+		AgentID: "planner-1", Role: schema.RolePlanner, State: schema.StateRunning,
+		Type: schema.TypeTaskSpawn, TaskID: "task-plan",
+	}
+	store.AddEvent(parent)
+
+	child := schema.CanonicalEvent{
+		Ts: base.Add(time.Second), RunID: "run-1", Provider: schema.ProviderClaude,
+		AgentID: "exec-1", ParentAgentID: "planner-1", Role: schema.RoleExecutor,
+		State: schema.StateRunning, Type: schema.TypeTaskSpawn, TaskID: "task-exec",
+	}
+	store.AddEvent(child)
+
+	update := child
+	update.Ts = base.Add(2 * time.Second)
+	update.Type = schema.TypeTaskUpdate
+	update.Payload, _ = json.Marshal(schema.TaskUpdatePayload{Progress: 40, Message: "halfway"})
+	store.AddEvent(update)
+
+	task := store.GetTask("task-exec")
+	if task.ParentTaskID != "task-plan" {
+		t.Errorf("expected parent task-plan, got %q", task.ParentTaskID)
+	}
+	if task.Progress != 40 || task.LastMessage != "halfway" {
+		t.Errorf("expected progress 40 and message 'halfway', got %d %q", task.Progress, task.LastMessage)
+	}
+
+	// Error then resume counts as a retry
+	failed := child
+	failed.Ts = base.Add(3 * time.Second)
+	failed.Type = schema.TypeError
+	failed.State = schema.StateError
+	store.AddEvent(failed)
+	if got := store.GetTask("task-exec").State; got != TaskFailed {
+		t.Errorf("expected failed after error, got %s", got)
+	}
+
+	recovered := child
+	recovered.Ts = base.Add(4 * time.Second)
+	recovered.Type = schema.TypeRecover
+	store.AddEvent(recovered)
+	task = store.GetTask("task-exec")
+	if task.State != TaskActive || task.Retries != 1 {
+		t.Errorf("expected active with 1 retry, got %s with %d", task.State, task.Retries)
+	}
+}
+
+func TestAgentLifecycleStats(t *testing.T) {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	add := func(state schema.AgentState, typ schema.EventType, offset time.Duration) {
+		store.AddEvent(schema.CanonicalEvent{
+			Ts: base.Add(offset), RunID: "run-1", Provider: schema.ProviderClaude,
+			AgentID: "agent-1", Role: schema.RoleExecutor, State: state, Type: typ,
+		})
+	}
+
+	add(schema.StateRunning, schema.TypeTaskSpawn, 0)
+	add(schema.StateRunning, schema.TypeToolCall, 5*time.Second)
+	add(schema.StateError, schema.TypeError, 10*time.Second)
+	add(schema.StateRunning, schema.TypeRecover, 14*time.Second)
+	add(schema.StateDone, schema.TypeTaskDone, 20*time.Second)
+
+	agent := store.GetAgent("agent-1")
+	if !agent.FirstSeen.Equal(base) {
+		t.Errorf("expected first seen %v, got %v", base, agent.FirstSeen)
+	}
+	if got := agent.TimeInState[schema.StateRunning]; got != 16*time.Second {
+		t.Errorf("expected 16s running, got %s", got)
+	}
+	if got := agent.TimeInState[schema.StateError]; got != 4*time.Second {
+		t.Errorf("expected 4s in error, got %s", got)
+	}
+	if agent.ErrorCount != 1 || agent.Recoveries != 1 {
+		t.Errorf("expected 1 error and 1 recovery, got %d and %d", agent.ErrorCount, agent.Recoveries)
+	}
+	if !agent.TerminalAt.Equal(base.Add(20 * time.Second)) {
+		t.Errorf("expected terminal at +20s, got %v", agent.TerminalAt)
+	}
+	if got := agent.Uptime(base.Add(time.Hour)); got != 20*time.Second {
+		t.Errorf("expected uptime frozen at 20s, got %s", got)
+	}
+	if got := agent.TimeIn(schema.StateDone, base.Add(30*time.Second)); got != 10*time.Second {
+		t.Errorf("expected 10s in current done state, got %s", got)
+	}
+}
+
+func TestGetters_ReturnCopies(t *testing.T) {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+	event := schema.CanonicalEvent{
+		Ts: base, RunID: "run-1", Provider: schema.ProviderClaude,
+		AgentID: "agent-1", Role: schema.RoleExecutor, State: schema.StateRunning,
+		Type: schema.TypeTaskSpawn, TaskID: "task-1",
+	}
+	store.AddEvent(event)
+
+	agent := store.GetAgent("agent-1")
+	all := store.GetAllAgents()
+	roots := store.GetRootAgents()
+	task := store.GetTask("task-1")
+	tasks := store.GetAllTasks()
+
+	// Later events update the Store's agent and task, not copies already
+	// handed out
+	event.Ts = base.Add(5 * time.Second)
+	event.State = schema.StateDone
+	event.Type = schema.TypeTaskDone
+	store.AddEvent(event)
+
+	for _, got := range []*AgentInfo{agent, all[0], roots[0]} {
+		if got.State != schema.StateRunning || len(got.TimeInState) != 0 {
+			t.Errorf("agent copy changed after AddEvent: %s %v", got.State, got.TimeInState)
+		}
+	}
+	for _, got := range []*TaskInfo{task, tasks[0]} {
+		if got.State != TaskActive || got.Progress != 0 {
+			t.Errorf("task copy changed after AddEvent: %s %d", got.State, got.Progress)
+		}
+	}
+	agent.TimeInState[schema.StateError] = time.Hour
+	if _, ok := store.GetAgent("agent-1").TimeInState[schema.StateError]; ok {
+		t.Error("writing to a copy changed the Store")
+	}
+}
+
+// TestGetters_ConcurrentReads reads agents and tasks while events are
+// added; run with -race to catch shared state.
+func TestGetters_ConcurrentReads(t *testing.T) {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+	changes, cancel := store.Subscribe(16)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		states := []schema.AgentState{schema.StateRunning, schema.StateWaiting}
+		for i := 0; i < 200; i++ {
+			store.AddEvent(schema.CanonicalEvent{
+				Ts: base.Add(time.Duration(i) * time.Second), RunID: "run-1", Provider: schema.ProviderClaude,
+				AgentID: "agent-1", Role: schema.RoleExecutor, State: states[i%2],
+				Type: schema.TypeTaskUpdate, TaskID: "task-1",
+			})
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		for _, agent := range store.GetAllAgents() {
+			_ = agent.TimeInState[schema.StateRunning]
+		}
+		for _, task := range store.GetAllTasks() {
+			_ = task.Updated
+		}
+		select {
+		case change := <-changes:
+			if change.Task != nil {
+				_ = change.Task.Duration
+			}
+		default:
+		}
+	}
+	wg.Wait()
+}
+
+func TestDetectStalls(t *testing.T) {
+	store := NewStore(100)
+	store.SetStallThreshold(time.Minute)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	running := schema.CanonicalEvent{
+		Ts: base, RunID: "run-1", Provider: schema.ProviderClaude,
+		AgentID: "agent-1", Role: schema.RoleExecutor, State: schema.StateRunning,
+		Type: schema.TypeToolCall,
+	}
+	store.AddEvent(running)
+	waiting := running
+	waiting.AgentID = "agent-2"
+	waiting.State = schema.StateWaiting
+	store.AddEvent(waiting)
+
+	if got := store.DetectStalls(base.Add(30 * time.Second)); len(got) != 0 {
+		t.Errorf("expected no stalls before threshold, got %v", got)
+	}
+	got := store.DetectStalls(base.Add(2 * time.Minute))
+	if len(got) != 1 || got[0] != "agent-1" {
+		t.Fatalf("expected agent-1 stalled, got %v", got)
+	}
+	if !store.GetAgent("agent-1").Stalled {
+		t.Error("expected stalled flag set")
+	}
+	if got := store.DetectStalls(base.Add(3 * time.Minute)); len(got) != 0 {
+		t.Errorf("already stalled agents should not be reported again, got %v", got)
+	}
+
+	running.Ts = base.Add(4 * time.Minute)
+	store.AddEvent(running)
+	if store.GetAgent("agent-1").Stalled {
+		t.Error("expected next event to clear stalled flag")
+	}
+
+	store.SetStallThreshold(0)
+	if got := store.DetectStalls(base.Add(time.Hour)); got != nil {
+		t.Errorf("expected detection disabled, got %v", got)
+	}
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// ChangeKind identifies what a Change describes.
+type ChangeKind string
+
+const (
+	ChangeEvent   ChangeKind = "event"   // an event was added
+	ChangeAgent   ChangeKind = "agent"   // an agent was created or updated
+	ChangeTask    ChangeKind = "task"    // a task was created or updated
+	ChangeMetrics ChangeKind = "metrics" // aggregate metrics changed
+	ChangeRun     ChangeKind = "run"     // a new run was seen
+)
+
+// Change is a typed delta published to subscribers after each event.
+// Only the field matching Kind is set. Agent, Task and Metrics are copies
+// of what the getters return at that moment, so subscribers can use them
+// without calling back into the Store.
+type Change struct {
+	Kind  ChangeKind
+	RunID string
+
+	Event   *schema.CanonicalEvent
+	Agent   *AgentInfo
+	Task    *TaskInfo
+	Metrics Metrics
+	Run     *RunInfo
+}
+
+// subscriber is a single Subscribe registration.
+type subscriber struct {
+	id int
+	ch chan Change
+}
+
+// Subscribe registers for change notifications. Each event added to the
+// Store produces a ChangeEvent followed by agent, task, metrics and run
+// changes as applicable. When a run is selected, agent, task and metrics
+// changes are only published for that run's events.
+//
+// Delivery never blocks the Store: when the channel's buffer is full the
+// oldest pending change is dropped to make room, so the newest state
+// always arrives. cancel unregisters and closes the channel.
+func (s *Store) Subscribe(buffer int) (<-chan Change, func()) {
+	if buffer <= 0 {
+		buffer = 64
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextSubID++
+	sub := &subscriber{id: s.nextSubID, ch: make(chan Change, buffer)}
+	s.subs = append(s.subs, sub)
+
+	cancel := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, other := range s.subs {
+			if other.id == sub.id {
+				s.subs = append(s.subs[:i], s.subs[i+1:]...)
+				close(sub.ch)
+				return
+			}
+		}
+	}
+	return sub.ch, cancel
+}
+
+// DroppedChanges returns the number of changes discarded because a
+// subscriber's buffer was full.
+func (s *Store) DroppedChanges() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dropped
+}
+
+// publishChanges notifies subscribers about an event that has just been
+// applied. newRun reports whether the event started a run.
+// The caller must hold s.mu.
+func (s *Store) publishChanges(event schema.CanonicalEvent, newRun bool) {
+	if len(s.subs) == 0 {
+		return
+	}
+
+	eventCopy := event
+	s.publish(Change{Kind: ChangeEvent, RunID: event.RunID, Event: &eventCopy})
+
+	if s.selected == "" || s.selected == event.RunID {
+		st := s.view()
+		if agent, ok := st.agents[event.AgentID]; ok {
+			s.publish(Change{Kind: ChangeAgent, RunID: event.RunID, Agent: agent.clone()})
+		}
+		if event.TaskID != "" {
+			if task, ok := st.tasks[event.TaskID]; ok {
+				s.publish(Change{Kind: ChangeTask, RunID: event.RunID, Task: task.clone()})
+			}
+		}
+		s.publish(Change{Kind: ChangeMetrics, RunID: event.RunID, Metrics: st.metrics})
+	}
+
+	if newRun {
+		if r, ok := s.runs[event.RunID]; ok {
+			info := r.snapshot()
+			s.publish(Change{Kind: ChangeRun, RunID: event.RunID, Run: &info})
+		}
+	}
+}
+
+// publish delivers a change to every subscriber without blocking,
+// evicting the oldest pending change from a full buffer.
+// The caller must hold s.mu.
+func (s *Store) publish(change Change) {
+	for _, sub := range s.subs {
+		select {
+		case sub.ch <- change:
+			continue
+		default:
+		}
+
+		select {
+		case <-sub.ch:
+			s.dropped++
+		default:
+		}
+		// Only publish sends, under s.mu, so there is room now
+		sub.ch <- change
+	}
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+// drain reads every change currently buffered in ch.
+func drain(ch <-chan Change) []Change {
+	var changes []Change
+	for {
+		select {
+		case c := <-ch:
+			changes = append(changes, c)
+		default:
+			return changes
+		}
+	}
+}
+
+func TestSubscribe_Deltas(t *testing.T) {
+	store := NewStore(100)
+	changes, cancel := store.Subscribe(16)
+	defer cancel()
+
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+	event := testEvent("run-1", "agent-1", schema.TypeMessage, schema.StateRunning, base)
+	event.TaskID = "task-1"
+	event.Type = schema.TypeTaskSpawn
+	event.Payload = []byte(`{"title":"build"}`)
+	store.AddEvent(event)
+
+	got := drain(changes)
+	kinds := make([]ChangeKind, len(got))
+	for i, c := range got {
+		kinds[i] = c.Kind
+	}
+	want := []ChangeKind{ChangeEvent, ChangeAgent, ChangeTask, ChangeMetrics, ChangeRun}
+	if len(kinds) != len(want) {
+		t.Fatalf("expected changes %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("expected changes %v, got %v", want, kinds)
+		}
+	}
+
+	if got[1].Agent.State != schema.StateRunning {
+		t.Errorf("expected agent state running, got %s", got[1].Agent.State)
+	}
+	if got[3].Metrics.EventCount != 1 {
+		t.Errorf("expected metrics event count 1, got %d", got[3].Metrics.EventCount)
+	}
+
+	// A second event in the same run does not announce the run again
+	store.AddEvent(testEvent("run-1", "agent-1", schema.TypeMessage, schema.StateDone, base.Add(time.Second)))
+	for _, c := range drain(changes) {
+		if c.Kind == ChangeRun {
+			t.Error("run change should only be published for a new run")
+		}
+	}
+}
+
+func TestSubscribe_SelectedRun(t *testing.T) {
+	store := NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+	store.AddEvent(testEvent("run-1", "agent-1", schema.TypeMessage, schema.StateRunning, base))
+	if err := store.SelectRun("run-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, cancel := store.Subscribe(16)
+	defer cancel()
+
+	store.AddEvent(testEvent("run-2", "agent-2", schema.TypeMessage, schema.StateRunning, base.Add(time.Second)))
+	for _, c := range drain(changes) {
+		if c.Kind == ChangeAgent || c.Kind == ChangeMetrics {
+			t.Errorf("unexpected %s change for an unselected run", c.Kind)
+		}
+	}
+}
+
+func TestSubscribe_DropAndCancel(t *testing.T) {
+	store := NewStore(100)
+	changes, cancel := store.Subscribe(1)
+
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+	store.AddEvent(testEvent("run-1", "agent-1", schema.TypeMessage, schema.StateRunning, base))
+
+	if store.DroppedChanges() == 0 {
+		t.Error("expected changes beyond the buffer to be dropped")
+	}
+	if got := drain(changes); len(got) != 1 || got[0].Kind != ChangeRun {
+		t.Errorf("expected only the newest change to be kept, got %+v", got)
+	}
+
+	cancel()
+	for range changes {
+	}
+	// Adding after cancel must not panic on the closed channel
+	store.AddEvent(testEvent("run-1", "agent-1", schema.TypeMessage, schema.StateDone, base.Add(time.Second)))
+}
@@ -59,6 +59,7 @@ type AgentCard struct {
 	Role    schema.Role
 	State   schema.AgentState
 	Summary string // latest event summary
+	Stalled bool   // running with no recent events; cleared by the next update
 }
 
 // NewModel creates a new Arena model.
@@ -95,6 +96,25 @@ func (m *Model) UpdateAgentWithSummary(agentID string, role schema.Role, state s
 	}
 }
 
+// SetStalled flags or clears an agent as stalled.
+func (m *Model) SetStalled(agentID string, stalled bool) {
+	if card, ok := m.agents[agentID]; ok {
+		card.Stalled = stalled
+	}
+}
+
+// Clone returns a deep copy whose cards can be updated independently.
+func (m Model) Clone() Model {
+	clone := m
+	clone.agents = make(map[string]*AgentCard, len(m.agents))
+	for id, card := range m.agents {
+		copied := *card
+		clone.agents[id] = &copied
+	}
+	clone.order = append([]string(nil), m.order...)
+	return clone
+}
+
 // SetSize updates the panel dimensions.
 func (m *Model) SetSize(width, height int) {
 	m.width = width
@@ -255,7 +275,7 @@ func renderCard(card *AgentCard, selected bool, useUnicode bool) string {
 	if card.State == schema.StateError || card.State == schema.StateFailed {
 		borderColor = "#FF7B72"
 	}
-	if card.State == schema.StateBlocked {
+	if card.State == schema.StateBlocked || card.Stalled {
 		borderColor = "#E3B341"
 	}
 
@@ -337,7 +357,11 @@ func renderCard(card *AgentCard, selected bool, useUnicode bool) string {
 	lines = append(lines, boxLine(idStyle.Render(truncate(card.AgentID, cardContentWidth)), borderStyle))
 
 	// State
-	lines = append(lines, boxLine(stateStyle.Render(string(card.State)), borderStyle))
+	stateText := string(card.State)
+	if card.Stalled {
+		stateText += " (stalled)"
+	}
+	lines = append(lines, boxLine(stateStyle.Render(truncate(stateText, cardContentWidth)), borderStyle))
 
 	// Recent activity label
 	lines = append(lines, boxLine(labelStyle.Render("Recent activity"), borderStyle))
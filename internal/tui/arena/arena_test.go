@@ -67,6 +67,22 @@ func TestUpdateAgent_ExistingAgent(t *testing.T) {
 	}
 }
 
+func TestClone_Independent(t *testing.T) {
+	m := NewModel()
+	m.UpdateAgent("agent-001", schema.RoleExecutor, schema.StateRunning)
+
+	clone := m.Clone()
+	m.UpdateAgent("agent-002", schema.RolePlanner, schema.StateRunning)
+	m.SetStalled("agent-001", true)
+
+	if clone.AgentCount() != 1 {
+		t.Errorf("Expected clone to keep 1 agent, got %d", clone.AgentCount())
+	}
+	if clone.agents["agent-001"].Stalled {
+		t.Error("Expected clone card to be unaffected by SetStalled")
+	}
+}
+
 func TestUpdateAgent_MultipleAgents(t *testing.T) {
 	m := NewModel()
 	m.UpdateAgent("agent-001", schema.RoleExecutor, schema.StateRunning)
@@ -932,3 +948,20 @@ func TestOrderStability(t *testing.T) {
 		}
 	}
 }
+
+func TestSetStalled(t *testing.T) {
+	m := NewModel()
+	m.SetSize(120, 20)
+	m.UpdateAgentWithSummary("agent-001", schema.RoleExecutor, schema.StateRunning, "tool call")
+	m.SetStalled("agent-001", true)
+
+	if !strings.Contains(StripAnsi(m.View()), "(stalled)") {
+		t.Error("Expected stalled marker in view")
+	}
+
+	// A fresh update clears the flag
+	m.UpdateAgentWithSummary("agent-001", schema.RoleExecutor, schema.StateRunning, "tool result")
+	if m.agents["agent-001"].Stalled {
+		t.Error("Expected update to clear stalled flag")
+	}
+}
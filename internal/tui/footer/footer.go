@@ -2,6 +2,8 @@ package footer
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/chamdom/omc-agent-tui/pkg/schema"
 	"github.com/charmbracelet/lipgloss"
@@ -19,8 +21,21 @@ type Model struct {
 	totalTokensIn  int
 	totalTokensOut int
 	totalCostUSD   float64
+	replay         *ReplayState
 }
 
+// ReplayState is the replay position shown in the scrubber.
+type ReplayState struct {
+	Position int // events applied so far
+	Total    int
+	Time     time.Time // virtual session time
+	Speed    float64
+	Playing  bool
+}
+
+// scrubberWidth is the number of cells in the replay progress bar.
+const scrubberWidth = 20
+
 // NewModel creates a new Footer model.
 func NewModel() Model {
 	return Model{
@@ -39,6 +54,12 @@ func (m *Model) IncrementErrors() {
 	m.errorCount++
 }
 
+// SetCounts overrides the event and error counters.
+func (m *Model) SetCounts(events, errors int) {
+	m.eventCount = events
+	m.errorCount = errors
+}
+
 // SetMode updates the current mode.
 func (m *Model) SetMode(mode schema.Mode) {
 	m.mode = mode
@@ -62,6 +83,11 @@ func (m *Model) SetMetrics(totalLatency float64, tokensIn, tokensOut int, costUS
 	m.totalCostUSD = costUSD
 }
 
+// SetReplay shows the replay scrubber; nil hides it.
+func (m *Model) SetReplay(state *ReplayState) {
+	m.replay = state
+}
+
 // SetStatus updates the status text.
 func (m *Model) SetStatus(status string) {
 	m.status = status
@@ -132,6 +158,10 @@ func (m Model) View() string {
 		)
 	}
 
+	if m.replay != nil {
+		parts = append(parts, metricsStyle.Render(renderScrubber(*m.replay)), "|")
+	}
+
 	parts = append(parts, statusStyle.Render(m.status))
 
 	if m.redacted {
@@ -151,6 +181,27 @@ func (m Model) View() string {
 	return style.Render(content)
 }
 
+// renderScrubber draws play state, speed, a progress bar, the event
+// position and the virtual time, e.g. "▶ 4x [█████░░░] 120/500 22:27:13".
+func renderScrubber(r ReplayState) string {
+	icon := "⏸"
+	if r.Playing {
+		icon = "▶"
+	}
+
+	filled := 0
+	if r.Total > 0 {
+		filled = r.Position * scrubberWidth / r.Total
+	}
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", scrubberWidth-filled)
+
+	clock := "--:--:--"
+	if !r.Time.IsZero() {
+		clock = r.Time.Format("15:04:05")
+	}
+	return fmt.Sprintf("%s %gx [%s] %d/%d %s", icon, r.Speed, bar, r.Position, r.Total, clock)
+}
+
 // formatCount formats large numbers with K/M suffixes.
 func formatCount(n int) string {
 	if n >= 1000000 {
@@ -3,6 +3,7 @@ package footer
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/chamdom/omc-agent-tui/pkg/schema"
 )
@@ -353,3 +354,27 @@ func TestView_CompleteScenario(t *testing.T) {
 		}
 	}
 }
+
+func TestSetReplay_RendersScrubber(t *testing.T) {
+	m := NewModel()
+	m.SetSize(200)
+	m.SetReplay(&ReplayState{
+		Position: 50,
+		Total:    100,
+		Time:     time.Date(2026, 2, 17, 22, 27, 13, 0, time.UTC),
+		Speed:    4,
+		Playing:  true,
+	})
+
+	view := m.View()
+	for _, want := range []string{"▶ 4x", "[██████████░░░░░░░░░░]", "50/100", "22:27:13"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected view to contain %q, got %q", want, view)
+		}
+	}
+
+	m.SetReplay(nil)
+	if strings.Contains(m.View(), "50/100") {
+		t.Error("Expected scrubber hidden after SetReplay(nil)")
+	}
+}
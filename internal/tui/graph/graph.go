@@ -86,6 +86,26 @@ func (m *Model) UpdateTaskState(taskID string, state string) {
 	}
 }
 
+// UpdateTaskTitle updates the title of a task.
+func (m *Model) UpdateTaskTitle(taskID string, title string) {
+	if task, exists := m.tasks[taskID]; exists {
+		task.Title = title
+	}
+}
+
+// Clone returns a deep copy whose tasks can be updated independently.
+func (m Model) Clone() Model {
+	clone := m
+	clone.tasks = make(map[string]*TaskNode, len(m.tasks))
+	for id, task := range m.tasks {
+		copied := *task
+		copied.Children = append([]string(nil), task.Children...)
+		clone.tasks[id] = &copied
+	}
+	clone.roots = append([]string(nil), m.roots...)
+	return clone
+}
+
 // SetSize updates the panel dimensions.
 func (m *Model) SetSize(width, height int) {
 	m.width = width
@@ -158,6 +178,9 @@ func (m Model) renderNode(taskID string, prefix string, isLast bool, isRoot bool
 		task.AgentID,
 		task.State,
 	)
+	if task.Title != "" && task.Title != task.TaskID {
+		nodeLine += " - " + task.Title
+	}
 	lines = append(lines, stateStyle.Render(nodeLine))
 
 	// Render children
@@ -84,6 +84,41 @@ func TestUpdateTaskState(t *testing.T) {
 	}
 }
 
+func TestUpdateTaskTitle(t *testing.T) {
+	m := NewModel()
+	m.AddTask("task-001", "executor", "task-001")
+	m.UpdateTaskTitle("task-001", "Fix login")
+	m.UpdateTaskTitle("task-404", "ignored")
+
+	if task := m.tasks["task-001"]; task.Title != "Fix login" {
+		t.Errorf("expected Title Fix login, got %s", task.Title)
+	}
+	if _, exists := m.tasks["task-404"]; exists {
+		t.Error("expected unknown tasks not to be created")
+	}
+
+	m.SetSize(60, 10)
+	if view := m.View(); !strings.Contains(view, "task-001 [executor] active - Fix login") {
+		t.Errorf("expected the title after the state, got:\n%s", view)
+	}
+}
+
+func TestClone(t *testing.T) {
+	m := NewModel()
+	m.AddTask("task-001", "planner", "Root")
+
+	clone := m.Clone()
+	m.AddChildTask("task-001", "task-002", "executor", "Child")
+	m.UpdateTaskState("task-001", "done")
+
+	if len(clone.tasks) != 1 {
+		t.Errorf("expected clone to keep 1 task, got %d", len(clone.tasks))
+	}
+	if got := clone.tasks["task-001"]; got.State != "active" || len(got.Children) != 0 {
+		t.Errorf("expected clone root unchanged, got state %s with %d children", got.State, len(got.Children))
+	}
+}
+
 func TestSetSize(t *testing.T) {
 	m := NewModel()
 	m.SetSize(100, 50)
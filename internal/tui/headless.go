@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"errors"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/internal/replay"
+	"github.com/chamdom/omc-agent-tui/internal/tui/arena"
+)
+
+// Frame is one screen rendered by RenderFrames.
+type Frame struct {
+	Index  int       // 0-based frame number
+	Cursor int       // events applied when the frame was taken
+	Time   time.Time // session time of the frame
+	View   string    // screen contents as plain text
+}
+
+// HeadlessOptions configures RenderFrames.
+type HeadlessOptions struct {
+	Width  int
+	Height int
+	// Every takes a frame at fixed steps of session time; zero takes one
+	// after each event
+	Every time.Duration
+}
+
+// RenderFrames replays player's playback range through m without a
+// terminal and passes each frame to emit, stopping at the first error.
+// Frames depend only on the events and options: stall detection follows
+// session time and styling is stripped, so the output suits golden-file
+// tests and printable post-mortems.
+func RenderFrames(m *Model, player *replay.Player, opts HeadlessOptions, emit func(Frame) error) error {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return errors.New("headless replay needs a positive width and height")
+	}
+
+	m.SetReplay(player)
+	m.width, m.height = opts.Width, opts.Height
+	m.updateLayout()
+
+	var frameTime time.Time
+	m.SetClock(func() time.Time { return frameTime })
+
+	index := 0
+	frame := func(cursor int, at time.Time) error {
+		frameTime = at
+		m.seekReplay(cursor)
+		if m.store != nil {
+			// Newly stalled agents arrive as agent changes, as on a tick
+			m.store.DetectStalls(at)
+			m.applyChanges()
+			// Uptimes follow the frame time even without changes
+			if m.inspected != "" {
+				m.inspectAgent(m.inspected)
+			}
+		}
+		m.updateScrubber()
+		err := emit(Frame{Index: index, Cursor: cursor, Time: at, View: arena.StripAnsi(m.View())})
+		index++
+		return err
+	}
+
+	from, to := player.Range()
+	if from >= to {
+		return nil
+	}
+	events := player.Events(from, to)
+	m.seekReplay(from)
+
+	if opts.Every <= 0 {
+		for i, event := range events {
+			if err := frame(from+i+1, event.Ts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	start, end := events[0].Ts, events[len(events)-1].Ts
+	cursor := from
+	for at := start; ; at = at.Add(opts.Every) {
+		for cursor < to && !events[cursor-from].Ts.After(at) {
+			cursor++
+		}
+		if err := frame(cursor, at); err != nil {
+			return err
+		}
+		if !at.Before(end) {
+			return nil
+		}
+	}
+}
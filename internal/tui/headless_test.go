@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func headlessEvents() []schema.CanonicalEvent {
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+	return []schema.CanonicalEvent{
+		{Ts: base, RunID: "run-1", Provider: schema.ProviderClaude, AgentID: "planner-1",
+			Role: schema.RolePlanner, State: schema.StateRunning, Type: schema.TypeTaskSpawn, TaskID: "task-1"},
+		{Ts: base.Add(5 * time.Second), RunID: "run-1", Provider: schema.ProviderClaude, AgentID: "exec-1",
+			ParentAgentID: "planner-1", Role: schema.RoleExecutor, State: schema.StateRunning,
+			Type: schema.TypeTaskSpawn, TaskID: "task-2"},
+		{Ts: base.Add(9 * time.Second), RunID: "run-1", Provider: schema.ProviderClaude, AgentID: "exec-1",
+			Role: schema.RoleExecutor, State: schema.StateDone, Type: schema.TypeTaskDone, TaskID: "task-2"},
+	}
+}
+
+func TestRenderFrames_Golden(t *testing.T) {
+	m, player := replayModelWith(t, headlessEvents())
+
+	var out strings.Builder
+	err := RenderFrames(&m, player, HeadlessOptions{Width: 100, Height: 30}, func(frame Frame) error {
+		fmt.Fprintf(&out, "--- frame %d: %d events, %s ---\n%s\n",
+			frame.Index, frame.Cursor, frame.Time.Format(time.RFC3339), frame.View)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RenderFrames failed: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "headless.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(out.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if out.String() != string(want) {
+		t.Errorf("frames differ from %s; run with -update if the change is intended\n%s", golden, out.String())
+	}
+}
+
+func TestRenderFrames_Every(t *testing.T) {
+	m, player := replayModelWith(t, headlessEvents())
+
+	var cursors []int
+	err := RenderFrames(&m, player, HeadlessOptions{Width: 100, Height: 30, Every: 4 * time.Second}, func(frame Frame) error {
+		cursors = append(cursors, frame.Cursor)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RenderFrames failed: %v", err)
+	}
+	// Frames at 0s, 4s, 8s and 12s
+	if fmt.Sprint(cursors) != "[1 1 2 3]" {
+		t.Errorf("expected cursors [1 1 2 3], got %v", cursors)
+	}
+}
+
+func TestRenderFrames_Stalls(t *testing.T) {
+	events := headlessEvents()[:2]
+	last := events[1]
+	last.Ts = last.Ts.Add(5 * time.Minute)
+	last.Type = schema.TypeMessage
+	m, player := replayModelWith(t, append(events, last))
+
+	var stalled []bool
+	err := RenderFrames(&m, player, HeadlessOptions{Width: 100, Height: 30, Every: time.Minute}, func(frame Frame) error {
+		stalled = append(stalled, strings.Contains(frame.View, "(stalled)"))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RenderFrames failed: %v", err)
+	}
+	// Frames every minute up to 6m; planner-1 idles past the 2m threshold
+	if fmt.Sprint(stalled) != "[false false true true true true true]" {
+		t.Errorf("expected stalls from the 2m frame on, got %v", stalled)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/chamdom/omc-agent-tui/pkg/schema"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -11,9 +12,19 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// AgentStats summarizes an agent's lifecycle for the Agent section.
+type AgentStats struct {
+	Uptime     time.Duration
+	InState    time.Duration // time in the current state
+	ErrorCount int
+	Recoveries int
+	Stalled    bool
+}
+
 // Model represents the Inspector panel state.
 type Model struct {
 	event    *schema.CanonicalEvent
+	agent    *AgentStats // stats for the event's agent, if known
 	viewport viewport.Model
 	width    int
 	height   int
@@ -33,6 +44,13 @@ func (m *Model) SetEvent(event *schema.CanonicalEvent) {
 	m.updateViewportContent()
 }
 
+// SetAgentStats sets lifecycle stats for the displayed event's agent.
+// nil hides the Agent section.
+func (m *Model) SetAgentStats(stats *AgentStats) {
+	m.agent = stats
+	m.updateViewportContent()
+}
+
 // ClearEvent clears the currently displayed event.
 func (m *Model) ClearEvent() {
 	m.event = nil
@@ -181,6 +199,28 @@ func (m *Model) renderEvent() string {
 		b.WriteString("\n")
 	}
 
+	// Agent lifecycle section
+	if a := m.agent; a != nil {
+		b.WriteString("\n")
+		b.WriteString(sectionStyle.Render("--- Agent ---"))
+		b.WriteString("\n")
+
+		b.WriteString(labelStyle.Render("Uptime:    "))
+		b.WriteString(a.Uptime.Round(time.Second).String())
+		b.WriteString("\n")
+
+		b.WriteString(labelStyle.Render("In state:  "))
+		b.WriteString(a.InState.Round(time.Second).String())
+		if a.Stalled {
+			b.WriteString(" (stalled)")
+		}
+		b.WriteString("\n")
+
+		b.WriteString(labelStyle.Render("Errors:    "))
+		fmt.Fprintf(&b, "%d (recovered %d)", a.ErrorCount, a.Recoveries)
+		b.WriteString("\n")
+	}
+
 	// Metrics section
 	if e.Metrics != nil {
 		b.WriteString("\n")
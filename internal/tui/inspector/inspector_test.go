@@ -272,3 +272,30 @@ func createTestEvent() schema.CanonicalEvent {
 		},
 	}
 }
+
+func TestSetAgentStats_RendersAgentSection(t *testing.T) {
+	m := NewModel()
+	m.SetSize(80, 40)
+
+	event := createTestEvent()
+	m.SetEvent(&event)
+	m.SetAgentStats(&AgentStats{
+		Uptime:     90 * time.Second,
+		InState:    30 * time.Second,
+		ErrorCount: 2,
+		Recoveries: 1,
+		Stalled:    true,
+	})
+
+	view := m.View()
+	for _, exp := range []string{"--- Agent ---", "1m30s", "30s (stalled)", "2 (recovered 1)"} {
+		if !strings.Contains(view, exp) {
+			t.Errorf("expected view to contain %q", exp)
+		}
+	}
+
+	m.SetAgentStats(nil)
+	if strings.Contains(m.View(), "--- Agent ---") {
+		t.Error("expected agent section hidden without stats")
+	}
+}
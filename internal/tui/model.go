@@ -1,10 +1,12 @@
 package tui
 
 import (
-	"encoding/json"
 	"fmt"
+	"log"
+	"sort"
 	"time"
 
+	"github.com/chamdom/omc-agent-tui/internal/replay"
 	"github.com/chamdom/omc-agent-tui/internal/store"
 	"github.com/chamdom/omc-agent-tui/internal/tui/arena"
 	"github.com/chamdom/omc-agent-tui/internal/tui/footer"
@@ -24,19 +26,52 @@ type EventMsg schema.CanonicalEvent
 // tickMsg is sent periodically to update the UI.
 type tickMsg time.Time
 
+// replayTickMsg drives replay playback.
+type replayTickMsg time.Time
+
 // Model is the root Bubbletea model for the TUI.
 type Model struct {
-	store      *store.Store
-	arena      arena.Model
-	timeline   timeline.Model
-	graph      graph.Model
-	inspector  inspector.Model
-	footer     footer.Model
-	agentTasks map[string]string // agentID -> active taskID
+	store     *store.Store
+	arena     arena.Model
+	timeline  timeline.Model
+	graph     graph.Model
+	inspector inspector.Model
+	footer    footer.Model
 
 	// agentEvents tracks the latest event per agent for inspector drill-down
 	agentEvents map[string]*schema.CanonicalEvent
 
+	// changes receives store deltas; every panel is updated from here
+	// after each store write instead of keeping its own bookkeeping
+	changes <-chan store.Change
+
+	// snapshotPath is where ctrl+s saves the store; "" disables saving
+	snapshotPath string
+	// flash marks a transient footer status, reverted on the next tick
+	flash bool
+
+	// clock is the time stall detection and agent stats measure against;
+	// nil uses the timestamp of the latest event
+	clock     func() time.Time
+	lastTs    time.Time
+	inspected string // agent whose stats the inspector shows
+
+	// player drives replay mode; nil in live mode
+	player *replay.Player
+	// applied counts the replayed events the panels reflect; it trails
+	// the player's cursor until the next sync
+	applied int
+	// checkpoints hold the replay state every checkpointEvery events so
+	// seeking backward does not re-apply everything from the start
+	checkpoints     []checkpoint
+	checkpointEvery int
+	// loadReport lists lines skipped while loading the replay; it is
+	// shown over the panels until a key dismisses it
+	loadReport *replay.LoadReport
+	// naming is set while a bookmark name is typed into the footer
+	naming       bool
+	bookmarkName string
+
 	width  int
 	height int
 
@@ -52,19 +87,30 @@ func NewModel(s *store.Store) Model {
 		graph:       graph.NewModel(),
 		inspector:   inspector.NewModel(),
 		footer:      footer.NewModel(),
-		agentTasks:  make(map[string]string),
 		agentEvents: make(map[string]*schema.CanonicalEvent),
+		clock:       time.Now,
 		focused:     0,
 	}
 	m.arena.SetFocused(true)
+	if s != nil {
+		// The subscription lives as long as the store; the program exits
+		// with it, so there is nothing to cancel.
+		m.changes, _ = s.Subscribe(1024)
+	}
 	return m
 }
 
 // Init initializes the model.
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
-		tickCmd(),
-	)
+	cmds := []tea.Cmd{tickCmd()}
+	if m.player != nil {
+		// Playback waits while the load report is shown
+		if m.loadReport == nil {
+			m.player.Play()
+		}
+		cmds = append(cmds, replayTickCmd())
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update handles messages and updates the model.
@@ -74,9 +120,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.player != nil && m.handleReplayKey(msg.String()) {
+			break
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
+		case "ctrl+s":
+			m.saveSnapshot()
 		case "tab":
 			m.focused = (m.focused + 1) % panelCount
 			m.arena.SetFocused(m.focused == 0)
@@ -86,6 +137,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if agent := m.arena.SelectedAgent(); agent != nil {
 					if evt, ok := m.agentEvents[agent.AgentID]; ok {
 						m.inspector.SetEvent(evt)
+						m.inspectAgent(agent.AgentID)
 					}
 				}
 			}
@@ -111,15 +163,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case EventMsg:
 		m.addEvent(schema.CanonicalEvent(msg))
 
+	case replayTickMsg:
+		if m.player != nil {
+			m.advanceReplay(time.Time(msg))
+			m.updateScrubber()
+			cmds = append(cmds, replayTickCmd())
+		}
+
 	case tickMsg:
+		if m.flash {
+			m.footer.SetStatus(m.baseStatus())
+			m.flash = false
+		}
 		if m.store != nil {
-			metrics := m.store.GetMetrics()
-			m.footer.SetMetrics(
-				metrics.TotalLatency,
-				metrics.TotalTokensIn,
-				metrics.TotalTokensOut,
-				metrics.TotalCostUSD,
-			)
+			// Newly stalled agents arrive as agent changes
+			m.store.DetectStalls(m.now())
+			m.applyChanges()
 		}
 		cmds = append(cmds, tickCmd())
 	}
@@ -133,6 +192,10 @@ func (m Model) View() string {
 		return "Initializing..."
 	}
 
+	if m.loadReport != nil {
+		return m.renderLoadReport()
+	}
+
 	// Top: Arena (full width)
 	arenaView := m.arena.View()
 
@@ -186,26 +249,144 @@ func (m *Model) updateLayout() {
 	m.footer.SetSize(width)
 }
 
-// addEvent processes a new event, updating store and all sub-models.
+// addEvent adds an event to the store and updates the panels from the
+// changes it publishes.
 func (m *Model) addEvent(event schema.CanonicalEvent) {
-	// Store event
-	if m.store != nil {
-		m.store.AddEvent(event)
+	if m.store == nil {
+		return
+	}
+	m.store.AddEvent(event)
+	m.applyChanges()
+}
+
+// SyncFromStore rebuilds the panels from the events retained by the store
+// without adding them to the store again. Used when the store was
+// restored from disk before the UI started.
+func (m *Model) SyncFromStore() {
+	if m.store == nil {
+		return
+	}
+	// Replay what the store would have published for its current state
+	for _, event := range m.store.GetEvents(0) {
+		m.applyChange(store.Change{Kind: store.ChangeEvent, Event: &event})
+	}
+	agents := m.store.GetAllAgents()
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].FirstSeen.Before(agents[j].FirstSeen)
+	})
+	for _, agent := range agents {
+		m.applyChange(store.Change{Kind: store.ChangeAgent, Agent: agent})
+	}
+	tasks := m.store.GetAllTasks()
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Created.Before(tasks[j].Created)
+	})
+	for _, task := range tasks {
+		m.applyChange(store.Change{Kind: store.ChangeTask, Task: task})
+	}
+
+	// Counters cover the whole history, not just the retained events
+	metrics := m.store.GetMetrics()
+	m.footer.SetCounts(metrics.EventCount, metrics.ErrorCount)
+	m.setMetrics(metrics)
+}
+
+// SetClock sets the time source used for stall detection and agent
+// stats. nil follows the timestamps of the events themselves, which suits
+// replayed or restored sessions.
+func (m *Model) SetClock(clock func() time.Time) {
+	m.clock = clock
+}
+
+// now returns the current time according to the model's clock.
+func (m *Model) now() time.Time {
+	if m.clock != nil {
+		return m.clock()
+	}
+	return m.lastTs
+}
+
+// SetSnapshotPath enables saving the store to path with ctrl+s.
+func (m *Model) SetSnapshotPath(path string) {
+	m.snapshotPath = path
+}
+
+// saveSnapshot writes the store to the snapshot path and reports the
+// outcome in the footer until the next tick.
+func (m *Model) saveSnapshot() {
+	if m.store == nil || m.snapshotPath == "" {
+		return
+	}
+	if err := m.store.SaveSnapshot(m.snapshotPath); err != nil {
+		log.Printf("[WARN] save snapshot: %v", err)
+		m.flashStatus("SNAPSHOT FAILED")
+	} else {
+		m.flashStatus("SNAPSHOT SAVED")
+	}
+}
+
+// flashStatus shows status in the footer until the next tick.
+func (m *Model) flashStatus(status string) {
+	m.footer.SetStatus(status)
+	m.flash = true
+}
+
+// applyChanges applies every change the store has published since the
+// last call. All store writes happen in Update, so draining right after
+// each one keeps the panels in step with the store and in order.
+func (m *Model) applyChanges() {
+	for {
+		select {
+		case change, ok := <-m.changes:
+			if !ok {
+				return
+			}
+			m.applyChange(change)
+		default:
+			return
+		}
+	}
+}
+
+// applyChange reacts to a delta published by the store.
+func (m *Model) applyChange(change store.Change) {
+	switch change.Kind {
+	case store.ChangeEvent:
+		m.applyEvent(*change.Event)
+	case store.ChangeMetrics:
+		m.setMetrics(change.Metrics)
+	case store.ChangeAgent:
+		m.applyAgent(change.Agent)
+	case store.ChangeTask:
+		m.applyTask(change.Task)
+	}
+}
+
+// setMetrics shows aggregate metrics in the footer.
+func (m *Model) setMetrics(metrics store.Metrics) {
+	m.footer.SetMetrics(
+		metrics.TotalLatency,
+		metrics.TotalTokensIn,
+		metrics.TotalTokensOut,
+		metrics.TotalCostUSD,
+	)
+}
+
+// applyEvent updates the timeline, footer counters and inspector for an
+// event the store has added.
+func (m *Model) applyEvent(event schema.CanonicalEvent) {
+	if event.Ts.After(m.lastTs) {
+		m.lastTs = event.Ts
 	}
 
 	// Update timeline
 	m.timeline.AddEvent(event)
 
-	// Build event summary for arena card
-	summary := buildEventSummary(event)
-
-	// Track latest event per agent for inspector drill-down
+	// Track latest event per agent for inspector drill-down and the
+	// arena card summary
 	eventCopy := event
 	m.agentEvents[event.AgentID] = &eventCopy
 
-	// Update arena with summary
-	m.arena.UpdateAgentWithSummary(event.AgentID, event.Role, event.State, summary)
-
 	// Update footer counters
 	m.footer.IncrementEvents()
 	if event.Type == schema.TypeError {
@@ -215,44 +396,61 @@ func (m *Model) addEvent(event schema.CanonicalEvent) {
 		m.footer.SetMode(event.Mode)
 	}
 
-	// Update graph for task lifecycle events
-	if event.TaskID != "" {
-		switch event.Type {
-		case schema.TypeTaskSpawn:
-			title := extractTaskTitle(event)
-			parentTaskID := ""
-			if event.ParentAgentID != "" {
-				if ptid, ok := m.agentTasks[event.ParentAgentID]; ok {
-					parentTaskID = ptid
-				}
-			}
-			if parentTaskID != "" {
-				m.graph.AddChildTask(parentTaskID, event.TaskID, event.AgentID, title)
-			} else {
-				m.graph.AddTask(event.TaskID, event.AgentID, title)
-			}
-			m.agentTasks[event.AgentID] = event.TaskID
+	// Update inspector with latest event
+	m.inspector.SetEvent(&event)
+	m.inspectAgent(event.AgentID)
+}
 
-		case schema.TypeTaskDone:
-			state := extractTaskDoneState(event)
-			m.graph.UpdateTaskState(event.TaskID, state)
-			delete(m.agentTasks, event.AgentID)
-		}
+// applyAgent updates the arena card of an agent the store has changed.
+func (m *Model) applyAgent(agent *store.AgentInfo) {
+	summary := ""
+	if event, ok := m.agentEvents[agent.AgentID]; ok {
+		summary = buildEventSummary(*event)
+	}
+	m.arena.UpdateAgentWithSummary(agent.AgentID, agent.Role, agent.State, summary)
+	m.arena.SetStalled(agent.AgentID, agent.Stalled)
+	if agent.AgentID == m.inspected {
+		m.inspectAgent(m.inspected)
 	}
+}
 
-	// Update inspector with latest event
-	m.inspector.SetEvent(&event)
+// applyTask updates the graph node of a task the store has changed. The
+// store links a task to the one its spawning agent was working on.
+func (m *Model) applyTask(task *store.TaskInfo) {
+	title := task.Title
+	if title == "" {
+		title = task.TaskID
+	}
+	if task.ParentTaskID != "" {
+		m.graph.AddChildTask(task.ParentTaskID, task.TaskID, task.AgentID, title)
+	} else {
+		m.graph.AddTask(task.TaskID, task.AgentID, title)
+	}
+	// Adding ignores known tasks, so a title set by a later spawn is
+	// applied here
+	m.graph.UpdateTaskTitle(task.TaskID, title)
+	m.graph.UpdateTaskState(task.TaskID, task.State)
+}
 
-	// Refresh metrics from store
-	if m.store != nil {
-		metrics := m.store.GetMetrics()
-		m.footer.SetMetrics(
-			metrics.TotalLatency,
-			metrics.TotalTokensIn,
-			metrics.TotalTokensOut,
-			metrics.TotalCostUSD,
-		)
+// inspectAgent shows agentID's lifecycle stats in the inspector.
+func (m *Model) inspectAgent(agentID string) {
+	if m.store == nil {
+		return
 	}
+	m.inspected = agentID
+	agent := m.store.GetAgent(agentID)
+	if agent == nil {
+		m.inspector.SetAgentStats(nil)
+		return
+	}
+	now := m.now()
+	m.inspector.SetAgentStats(&inspector.AgentStats{
+		Uptime:     agent.Uptime(now),
+		InState:    agent.TimeIn(agent.State, now),
+		ErrorCount: agent.ErrorCount,
+		Recoveries: agent.Recoveries,
+		Stalled:    agent.Stalled,
+	})
 }
 
 // AddEvent is the public API for adding events externally.
@@ -301,35 +499,6 @@ func truncateStr(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-// extractTaskTitle gets the title from a TaskSpawn payload.
-func extractTaskTitle(event schema.CanonicalEvent) string {
-	if len(event.Payload) > 0 {
-		var payload schema.TaskSpawnPayload
-		if err := json.Unmarshal(event.Payload, &payload); err == nil && payload.Title != "" {
-			return payload.Title
-		}
-	}
-	return event.TaskID
-}
-
-// extractTaskDoneState maps TaskDone result to graph state string.
-func extractTaskDoneState(event schema.CanonicalEvent) string {
-	if len(event.Payload) > 0 {
-		var payload schema.TaskDonePayload
-		if err := json.Unmarshal(event.Payload, &payload); err == nil {
-			switch payload.Result {
-			case "success":
-				return "done"
-			case "failure":
-				return "failed"
-			case "cancelled":
-				return "cancelled"
-			}
-		}
-	}
-	return "done"
-}
-
 // tickCmd returns a command that sends a tick message every second.
 func tickCmd() tea.Cmd {
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
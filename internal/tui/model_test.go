@@ -1,9 +1,14 @@
 package tui
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/chamdom/omc-agent-tui/internal/replay"
 	"github.com/chamdom/omc-agent-tui/internal/store"
 	"github.com/chamdom/omc-agent-tui/pkg/schema"
 	tea "github.com/charmbracelet/bubbletea"
@@ -167,11 +172,13 @@ func TestModelGraphIntegration(t *testing.T) {
 	if s.EventCount() != 2 {
 		t.Errorf("Expected 2 events in store, got %d", s.EventCount())
 	}
-	if m.agentTasks["agent-1"] != "task-001" {
-		t.Errorf("Expected agent-1 mapped to task-001, got %s", m.agentTasks["agent-1"])
+	m.graph.SetSize(60, 10)
+	view := m.graph.View()
+	if !strings.Contains(view, "task-001 [agent-1] active") {
+		t.Errorf("Expected task-001 as an active root, got:\n%s", view)
 	}
-	if m.agentTasks["agent-2"] != "task-002" {
-		t.Errorf("Expected agent-2 mapped to task-002, got %s", m.agentTasks["agent-2"])
+	if !strings.Contains(view, "└── task-002 [agent-2] active") {
+		t.Errorf("Expected task-002 nested under task-001, got:\n%s", view)
 	}
 
 	doneEvent := schema.CanonicalEvent{
@@ -186,13 +193,41 @@ func TestModelGraphIntegration(t *testing.T) {
 	}
 	m.AddEvent(doneEvent)
 
-	if _, ok := m.agentTasks["agent-2"]; ok {
-		t.Error("agent-2 should be removed from agentTasks after TaskDone")
+	if view := m.graph.View(); !strings.Contains(view, "task-002 [agent-2] done") {
+		t.Errorf("Expected task-002 done after TaskDone, got:\n%s", view)
 	}
 }
 
 // New integration tests for Arena features
 
+func TestModelGraphTitleUpdate(t *testing.T) {
+	s := store.NewStore(100)
+	m := NewModel(s)
+	m.graph.SetSize(60, 10)
+
+	spawn := schema.CanonicalEvent{
+		Ts:       time.Now(),
+		RunID:    "test-run",
+		Provider: schema.ProviderClaude,
+		AgentID:  "agent-1",
+		Role:     schema.RoleExecutor,
+		State:    schema.StateRunning,
+		Type:     schema.TypeTaskSpawn,
+		TaskID:   "task-001",
+	}
+	m.AddEvent(spawn)
+	if view := m.graph.View(); !strings.Contains(view, "task-001 [agent-1]") {
+		t.Fatalf("Expected the task ID as title before one is known, got:\n%s", view)
+	}
+
+	spawn.Ts = time.Now()
+	spawn.Payload = json.RawMessage(`{"title":"Fix login"}`)
+	m.AddEvent(spawn)
+	if view := m.graph.View(); !strings.Contains(view, "task-001 [agent-1] active - Fix login") {
+		t.Errorf("Expected the title from the later spawn, got:\n%s", view)
+	}
+}
+
 func TestModelArenaKeyNavigation(t *testing.T) {
 	m := NewModel(store.NewStore(100))
 
@@ -364,3 +399,368 @@ func TestModelPanelCoexistence(t *testing.T) {
 		t.Errorf("Expected 3 agents in arena, got %d", model.arena.AgentCount())
 	}
 }
+
+func TestModelSyncFromStore(t *testing.T) {
+	s := store.NewStore(100)
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+
+	// Events already in the store, e.g. restored from disk
+	s.AddEvent(schema.CanonicalEvent{
+		Ts: base, RunID: "run-1", Provider: schema.ProviderClaude,
+		AgentID: "exec-1", Role: schema.RoleExecutor, State: schema.StateRunning,
+		Type: schema.TypeTaskSpawn, TaskID: "task-1",
+	})
+	s.AddEvent(schema.CanonicalEvent{
+		Ts: base.Add(time.Second), RunID: "run-1", Provider: schema.ProviderClaude,
+		AgentID: "exec-1", Role: schema.RoleExecutor, State: schema.StateError,
+		Type: schema.TypeError, TaskID: "task-1",
+	})
+
+	m := NewModel(s)
+	m.SyncFromStore()
+
+	if s.EventCount() != 2 {
+		t.Errorf("sync must not re-add events to the store, got %d", s.EventCount())
+	}
+	if m.arena.AgentCount() != 1 {
+		t.Errorf("expected 1 arena agent, got %d", m.arena.AgentCount())
+	}
+	m.graph.SetSize(60, 10)
+	if view := m.graph.View(); !strings.Contains(view, "task-1 [exec-1] failed") {
+		t.Errorf("graph should be rebuilt with the failed task, got:\n%s", view)
+	}
+}
+
+func TestModelStoreChanges(t *testing.T) {
+	s := store.NewStore(100)
+	m := NewModel(s)
+	m.footer.SetSize(200)
+
+	cost := 0.25
+	m.AddEvent(schema.CanonicalEvent{
+		Ts: time.Now(), RunID: "run-1", Provider: schema.ProviderClaude,
+		AgentID: "exec-1", Role: schema.RoleExecutor, State: schema.StateRunning,
+		Type: schema.TypeMessage, Metrics: &schema.EventMetrics{CostUSD: &cost},
+	})
+
+	view := m.footer.View()
+	if !strings.Contains(view, "0.25") {
+		t.Errorf("footer should show cost from the metrics change, got %q", view)
+	}
+
+	// A stall flagged by the store on a tick reaches the arena card
+	s.SetStallThreshold(time.Minute)
+	m.SetClock(func() time.Time { return time.Now().Add(time.Hour) })
+	updated, _ := m.Update(tickMsg(time.Now()))
+	m = updated.(Model)
+	if card := m.arena.SelectedAgent(); card == nil || !card.Stalled {
+		t.Errorf("arena card should be stalled, got %+v", card)
+	}
+
+	// An error fails the task in the store, and so in the graph
+	m.AddEvent(schema.CanonicalEvent{
+		Ts: time.Now(), RunID: "run-1", Provider: schema.ProviderClaude,
+		AgentID: "exec-1", Role: schema.RoleExecutor, State: schema.StateError,
+		Type: schema.TypeError, TaskID: "task-1",
+	})
+	m.graph.SetSize(60, 10)
+	if view := m.graph.View(); !strings.Contains(view, "task-1 [exec-1] failed") {
+		t.Errorf("graph should show the task failed, got:\n%s", view)
+	}
+	if card := m.arena.SelectedAgent(); card == nil || card.Stalled || card.State != schema.StateError {
+		t.Errorf("arena card should follow the new event, got %+v", card)
+	}
+}
+
+func TestModelSaveSnapshot(t *testing.T) {
+	s := store.NewStore(100)
+	m := NewModel(s)
+	path := filepath.Join(t.TempDir(), "out.snap")
+	m.SetSnapshotPath(path)
+
+	m.AddEvent(schema.CanonicalEvent{
+		Ts: time.Now(), RunID: "run-1", Provider: schema.ProviderClaude,
+		AgentID: "exec-1", Role: schema.RoleExecutor, State: schema.StateRunning,
+		Type: schema.TypeMessage,
+	})
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+
+	restored := store.NewStore(100)
+	if err := restored.LoadSnapshot(path); err != nil {
+		t.Fatalf("snapshot not saved: %v", err)
+	}
+	if restored.GetAgent("exec-1") == nil {
+		t.Error("restored snapshot should contain exec-1")
+	}
+}
+
+func replayModel(t *testing.T) (Model, *replay.Player) {
+	t.Helper()
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+	var events []schema.CanonicalEvent
+	for i, agent := range []string{"planner-1", "exec-1", "exec-2", "exec-3"} {
+		events = append(events, schema.CanonicalEvent{
+			Ts: base.Add(time.Duration(i) * time.Second), RunID: "run-1",
+			Provider: schema.ProviderClaude, AgentID: agent, Role: schema.RoleExecutor,
+			State: schema.StateRunning, Type: schema.TypeTaskSpawn, TaskID: "task-" + agent,
+		})
+	}
+	return replayModelWith(t, events)
+}
+
+// replayModelWith returns a model replaying events from a temporary file.
+func replayModelWith(t *testing.T, events []schema.CanonicalEvent) (Model, *replay.Player) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	var lines []string
+	for _, event := range events {
+		data, _ := json.Marshal(event)
+		lines = append(lines, string(data))
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	player := replay.NewPlayer()
+	if err := player.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	m := NewModel(store.NewStore(100))
+	m.SetReplay(player)
+	return m, player
+}
+
+func TestModelReplayControls(t *testing.T) {
+	m, player := replayModel(t)
+
+	press := func(key tea.KeyMsg) {
+		updated, _ := m.Update(key)
+		m = updated.(Model)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	if player.Cursor() != 2 || m.arena.AgentCount() != 2 {
+		t.Fatalf("expected 2 events applied, got cursor %d with %d agents", player.Cursor(), m.arena.AgentCount())
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEnd})
+	if m.store.EventCount() != 4 {
+		t.Errorf("expected all 4 events after end, got %d", m.store.EventCount())
+	}
+
+	// Stepping back rebuilds from scratch instead of keeping later agents
+	press(tea.KeyMsg{Type: tea.KeyLeft})
+	if player.Cursor() != 3 || m.store.EventCount() != 3 || m.arena.AgentCount() != 3 {
+		t.Errorf("expected state at 3 events, got cursor %d, store %d, arena %d",
+			player.Cursor(), m.store.EventCount(), m.arena.AgentCount())
+	}
+	if m.store.GetAgent("exec-3") != nil {
+		t.Error("exec-3 should not exist before its spawn event")
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyHome})
+	if m.store.EventCount() != 0 || m.arena.AgentCount() != 0 {
+		t.Errorf("expected empty state at home, got %d events", m.store.EventCount())
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	if player.Speed() != 8 {
+		t.Errorf("expected speed 8x, got %v", player.Speed())
+	}
+
+	press(tea.KeyMsg{Type: tea.KeySpace})
+	if !player.IsPlaying() {
+		t.Error("expected space to start playback")
+	}
+	press(tea.KeyMsg{Type: tea.KeySpace})
+	if player.IsPlaying() {
+		t.Error("expected space to pause playback")
+	}
+}
+
+func TestModelReplayNavigation(t *testing.T) {
+	base := time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC)
+	var events []schema.CanonicalEvent
+	for i, typ := range []schema.EventType{
+		schema.TypeMessage, schema.TypeError, schema.TypeMessage, schema.TypeReplan, schema.TypeMessage,
+	} {
+		state := schema.StateRunning
+		if typ == schema.TypeError {
+			state = schema.StateError
+		}
+		events = append(events, schema.CanonicalEvent{
+			Ts: base.Add(time.Duration(i) * time.Second), RunID: "run-1",
+			Provider: schema.ProviderClaude, AgentID: "exec-1", Role: schema.RoleExecutor,
+			State: state, Type: typ,
+		})
+	}
+	m, player := replayModelWith(t, events)
+
+	press := func(keys ...string) {
+		for _, key := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+			switch key {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "end":
+				msg = tea.KeyMsg{Type: tea.KeyEnd}
+			}
+			updated, _ := m.Update(msg)
+			m = updated.(Model)
+		}
+	}
+
+	press("n")
+	if player.Cursor() != 2 {
+		t.Errorf("expected n to stop on the error, got cursor %d", player.Cursor())
+	}
+	press("n")
+	if player.Cursor() != 4 {
+		t.Errorf("expected n to stop on the replan, got cursor %d", player.Cursor())
+	}
+	press("N")
+	if player.Cursor() != 2 || m.store.EventCount() != 2 {
+		t.Errorf("expected N to rewind to the error, got cursor %d", player.Cursor())
+	}
+
+	press("M", "o", "o", "p", "s", "enter")
+	bookmarks := player.Bookmarks()
+	if len(bookmarks) != 1 || bookmarks[0].Name != "oops" || bookmarks[0].Position != 1 {
+		t.Fatalf("expected bookmark oops at event 1, got %+v", bookmarks)
+	}
+
+	press("end", "'")
+	if player.Cursor() != 2 {
+		t.Errorf("expected ' to jump to the bookmark, got cursor %d", player.Cursor())
+	}
+}
+
+func TestModelReplayTick(t *testing.T) {
+	m, player := replayModel(t)
+	player.Play()
+
+	updated, cmd := m.Update(replayTickMsg(time.Now()))
+	m = updated.(Model)
+	if cmd == nil {
+		t.Error("expected the next replay tick to be scheduled")
+	}
+	if m.store.EventCount() != 1 {
+		t.Errorf("expected the first event applied on the first tick, got %d", m.store.EventCount())
+	}
+}
+
+func TestModelReplayCheckpoints(t *testing.T) {
+	seek := func(m Model, positions ...int) Model {
+		for _, n := range positions {
+			m.seekReplay(n)
+		}
+		updated, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 50})
+		return updated.(Model)
+	}
+
+	rewound, _ := replayModel(t)
+	rewound.checkpointEvery = 2
+	rewound = seek(rewound, 4, 3)
+	if len(rewound.checkpoints) != 2 {
+		t.Fatalf("expected checkpoints at 2 and 4, got %d", len(rewound.checkpoints))
+	}
+
+	direct, _ := replayModel(t)
+	direct = seek(direct, 3)
+
+	if rewound.View() != direct.View() {
+		t.Error("expected the rewound view to match a direct replay to the same position")
+	}
+	if got, want := rewound.store.GetMetrics(), direct.store.GetMetrics(); got != want {
+		t.Errorf("expected metrics %+v, got %+v", want, got)
+	}
+	if rewound.store.GetAgent("exec-3") != nil {
+		t.Error("exec-3 should not exist before its spawn event")
+	}
+
+	// Stepping forward again continues from the restored state
+	rewound = seek(rewound, 4)
+	if rewound.store.EventCount() != 4 || rewound.arena.AgentCount() != 4 {
+		t.Errorf("expected 4 events after stepping forward, got %d", rewound.store.EventCount())
+	}
+}
+
+func TestModelCheckpointThinning(t *testing.T) {
+	m := NewModel(nil)
+	m.checkpointEvery = 1
+	for pos := 1; pos <= maxCheckpoints+1; pos++ {
+		m.saveCheckpoint(pos)
+	}
+
+	if len(m.checkpoints) > maxCheckpoints {
+		t.Errorf("expected at most %d checkpoints, got %d", maxCheckpoints, len(m.checkpoints))
+	}
+	if m.checkpointEvery != 2 {
+		t.Errorf("expected spacing to double to 2, got %d", m.checkpointEvery)
+	}
+	for _, cp := range m.checkpoints {
+		if cp.pos%2 != 0 {
+			t.Errorf("expected only even checkpoints after thinning, found %d", cp.pos)
+		}
+	}
+}
+
+func TestModelLoadReportOverlay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crashed.jsonl")
+	data, _ := json.Marshal(schema.CanonicalEvent{
+		Ts: time.Date(2026, 2, 17, 10, 0, 0, 0, time.UTC), RunID: "run-1",
+		Provider: schema.ProviderClaude, AgentID: "exec-1", Role: schema.RoleExecutor,
+		State: schema.StateRunning, Type: schema.TypeMessage,
+	})
+	if err := os.WriteFile(path, append(data, []byte("\n{\"ts\":")...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	player := replay.NewPlayer()
+	player.SetTolerant(true)
+	if err := player.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	m := NewModel(store.NewStore(100))
+	m.SetReplay(player)
+	m.Init()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+
+	if player.IsPlaying() {
+		t.Error("expected playback to wait for the report to be dismissed")
+	}
+	if view := m.View(); !strings.Contains(view, "skipped 1 line(s)") || !strings.Contains(view, "crashed.jsonl:2: invalid JSON") {
+		t.Errorf("expected the load report overlay, got:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.loadReport != nil || !player.IsPlaying() {
+		t.Error("expected a key to dismiss the report and start playback")
+	}
+}
+
+func TestModelReplayLoopRange(t *testing.T) {
+	m, player := replayModel(t)
+	player.SetRange(2, -1)
+	player.SetLoop(true)
+	m.Init()
+
+	now := time.Now()
+	updated, _ := m.Update(replayTickMsg(now))
+	m = updated.(Model)
+	if m.applied != 2 || m.store.EventCount() != 2 {
+		t.Fatalf("expected the events before the range applied, got %d", m.store.EventCount())
+	}
+
+	updated, _ = m.Update(replayTickMsg(now.Add(time.Minute)))
+	m = updated.(Model)
+	if !player.IsPlaying() || player.Cursor() != 2 {
+		t.Errorf("expected playback to loop to 2, got cursor %d", player.Cursor())
+	}
+	if m.store.EventCount() != 2 || m.arena.AgentCount() != 2 {
+		t.Errorf("expected panels rewound to 2 events, got store %d arena %d", m.store.EventCount(), m.arena.AgentCount())
+	}
+}
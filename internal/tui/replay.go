@@ -0,0 +1,457 @@
+package tui
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"log"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/chamdom/omc-agent-tui/internal/replay"
+	"github.com/chamdom/omc-agent-tui/internal/tui/arena"
+	"github.com/chamdom/omc-agent-tui/internal/tui/footer"
+	"github.com/chamdom/omc-agent-tui/internal/tui/graph"
+	"github.com/chamdom/omc-agent-tui/internal/tui/inspector"
+	"github.com/chamdom/omc-agent-tui/internal/tui/timeline"
+	"github.com/chamdom/omc-agent-tui/pkg/schema"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// replayTickInterval is how often replay playback advances.
+const replayTickInterval = 50 * time.Millisecond
+
+// Checkpoint spacing for replay seeks. Checkpoints start every
+// defaultCheckpointEvery events; once more than maxCheckpoints exist every
+// other one is dropped and the spacing doubles, so long replays keep
+// memory bounded while seeks stay proportional to the spacing.
+//
+// A checkpoint is dominated by its store snapshot, which holds the whole
+// event ring: about 6MB of JSON for a full 10,000-event ring, kept
+// gzip-compressed at roughly 300KB. The panel copies are small next to
+// it (the timeline keeps 100 events), so all checkpoints together stay
+// around 5MB.
+const (
+	defaultCheckpointEvery = 1000
+	maxCheckpoints         = 16
+)
+
+// replaySpeeds maps speed keys to playback speeds.
+var replaySpeeds = map[string]float64{"1": 1, "2": 4, "3": 8, "4": 16}
+
+// SetReplay switches the model to replay mode driven by player. Playback
+// starts when the program starts; keys then control the player and the
+// footer shows a scrubber. Stall detection follows replayed event time.
+// If lines were skipped while loading, their report is shown first and
+// playback starts once it is dismissed.
+func (m *Model) SetReplay(player *replay.Player) {
+	m.player = player
+	m.applied = 0
+	m.loadReport = nil
+	if report := player.Report(); len(report.Skipped) > 0 {
+		m.loadReport = &report
+	}
+	m.clock = nil
+	m.checkpoints = nil
+	m.checkpointEvery = defaultCheckpointEvery
+	m.footer.SetStatus(m.baseStatus())
+	m.updateScrubber()
+}
+
+// baseStatus is the footer status outside transient messages.
+func (m *Model) baseStatus() string {
+	if m.player != nil {
+		return "REPLAY"
+	}
+	return "LIVE"
+}
+
+// handleReplayKey applies a replay control key. Returns true if the key
+// was consumed.
+func (m *Model) handleReplayKey(key string) bool {
+	p := m.player
+	cursor := p.Cursor()
+	jump := p.Total() / 10
+	if jump < 1 {
+		jump = 1
+	}
+
+	if m.loadReport != nil && key != "ctrl+c" {
+		m.loadReport = nil
+		m.playReplay()
+		m.updateScrubber()
+		return true
+	}
+	if m.naming && key != "ctrl+c" {
+		m.handleBookmarkKey(key)
+		return true
+	}
+
+	switch key {
+	case "n":
+		n, ok := p.NextNotable(cursor - 1)
+		m.jumpTo(n.Position, ok, strings.ToUpper(string(n.Kind)))
+	case "N":
+		n, ok := p.PrevNotable(cursor - 1)
+		m.jumpTo(n.Position, ok, strings.ToUpper(string(n.Kind)))
+	case "M":
+		m.naming = true
+		m.bookmarkName = ""
+		m.flash = false
+		m.showBookmarkPrompt()
+	case "'":
+		bm, ok := p.NextBookmark(cursor - 1)
+		m.jumpTo(bm.Position, ok, "BOOKMARK: "+bm.Name)
+	case " ", "space":
+		if p.IsPlaying() {
+			p.Pause()
+		} else {
+			m.playReplay()
+		}
+	case "right":
+		m.seekReplay(cursor + 1)
+	case "left":
+		m.seekReplay(cursor - 1)
+	case "shift+right":
+		m.seekReplay(cursor + jump)
+	case "shift+left":
+		m.seekReplay(cursor - jump)
+	case "home":
+		m.seekReplay(0)
+	case "end":
+		m.seekReplay(p.Total())
+	default:
+		speed, ok := replaySpeeds[key]
+		if !ok {
+			return false
+		}
+		p.SetSpeed(speed)
+	}
+	m.updateScrubber()
+	return true
+}
+
+// jumpTo seeks so that event position is the latest one applied and
+// flashes label in the footer, or reports that there is nowhere to go.
+func (m *Model) jumpTo(position int, ok bool, label string) {
+	if !ok {
+		m.flashStatus("NOT FOUND")
+		return
+	}
+	m.seekReplay(position + 1)
+	m.flashStatus(label)
+}
+
+// handleBookmarkKey edits the bookmark name being typed. Enter saves the
+// bookmark at the current event, defaulting the name to its time; esc
+// cancels.
+func (m *Model) handleBookmarkKey(key string) {
+	switch key {
+	case "esc":
+		m.naming = false
+		m.footer.SetStatus(m.baseStatus())
+	case "enter":
+		m.naming = false
+		m.saveBookmark(m.bookmarkName)
+	case "backspace":
+		if r := []rune(m.bookmarkName); len(r) > 0 {
+			m.bookmarkName = string(r[:len(r)-1])
+		}
+		m.showBookmarkPrompt()
+	case "space":
+		m.bookmarkName += " "
+		m.showBookmarkPrompt()
+	default:
+		if len([]rune(key)) == 1 {
+			m.bookmarkName += key
+			m.showBookmarkPrompt()
+		}
+	}
+}
+
+// showBookmarkPrompt shows the bookmark name being typed in the footer.
+func (m *Model) showBookmarkPrompt() {
+	m.footer.SetStatus("BOOKMARK: " + m.bookmarkName + "_")
+}
+
+// saveBookmark bookmarks the latest applied event under name.
+func (m *Model) saveBookmark(name string) {
+	position := m.player.Cursor() - 1
+	if position < 0 {
+		position = 0
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = m.player.VirtualTime().Format("15:04:05")
+	}
+	if err := m.player.AddBookmark(name, position); err != nil {
+		log.Printf("[WARN] save bookmark: %v", err)
+		m.flashStatus("BOOKMARK FAILED")
+		return
+	}
+	m.flashStatus(fmt.Sprintf("BOOKMARKED %q", name))
+}
+
+// playReplay starts playback. The player may first move its cursor to
+// the start of its range, which the panels follow.
+func (m *Model) playReplay() {
+	m.player.Play()
+	m.syncReplay()
+}
+
+// advanceReplay applies the events that became due at now. If playback
+// looped back to the start of its range, the panels are rewound with it.
+func (m *Model) advanceReplay(now time.Time) {
+	// Follow cursor moves made outside the model, such as Play in Init
+	m.syncReplay()
+	m.applyReplay(m.player.Advance(now))
+	m.syncReplay()
+}
+
+// syncReplay brings the panels to the player's cursor if they differ.
+func (m *Model) syncReplay() {
+	if cursor := m.player.Cursor(); cursor != m.applied {
+		m.seekReplay(cursor)
+	}
+}
+
+// seekReplay moves replay to the point where events [0, n) have been
+// applied. Moving forward applies the missing events. Moving backward
+// restores the nearest checkpoint at or before n, or starts over from an
+// empty store, and re-applies the events after it, so every panel shows
+// exactly the state after event n.
+func (m *Model) seekReplay(n int) {
+	p := m.player
+	total := p.Total()
+	if n < 0 {
+		n = 0
+	}
+	if n > total {
+		n = total
+	}
+
+	if n < m.applied {
+		m.rewind(n)
+	}
+	m.applyReplay(p.Events(m.applied, n))
+	p.SetCursor(n)
+}
+
+// applyReplay applies the replayed events that follow the ones already
+// applied, recording a checkpoint whenever a multiple of the checkpoint
+// spacing is reached.
+func (m *Model) applyReplay(events []schema.CanonicalEvent) {
+	for _, event := range events {
+		m.addEvent(event)
+		m.applied++
+		if m.applied%m.checkpointEvery == 0 {
+			m.saveCheckpoint(m.applied)
+		}
+	}
+}
+
+// checkpoint is the complete model state after the first pos replayed
+// events.
+type checkpoint struct {
+	pos   int
+	store []byte // gzip-compressed store snapshot; nil without a store
+
+	arena       arena.Model
+	timeline    timeline.Model
+	graph       graph.Model
+	inspector   inspector.Model
+	footer      footer.Model
+	agentEvents map[string]*schema.CanonicalEvent
+	lastTs      time.Time
+	inspected   string
+}
+
+// saveCheckpoint records the state after pos events unless a checkpoint
+// at or past pos already exists.
+func (m *Model) saveCheckpoint(pos int) {
+	if n := len(m.checkpoints); n > 0 && m.checkpoints[n-1].pos >= pos {
+		return
+	}
+
+	cp := checkpoint{
+		pos:         pos,
+		arena:       m.arena.Clone(),
+		timeline:    m.timeline,
+		graph:       m.graph.Clone(),
+		inspector:   m.inspector,
+		footer:      m.footer,
+		agentEvents: maps.Clone(m.agentEvents),
+		lastTs:      m.lastTs,
+		inspected:   m.inspected,
+	}
+	if m.store != nil {
+		var buf bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+		err := m.store.Snapshot(zw)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			log.Printf("[WARN] replay checkpoint at %d: %v", pos, err)
+			return
+		}
+		cp.store = buf.Bytes()
+	}
+	m.checkpoints = append(m.checkpoints, cp)
+
+	if len(m.checkpoints) > maxCheckpoints {
+		m.checkpointEvery *= 2
+		kept := m.checkpoints[:0]
+		for _, c := range m.checkpoints {
+			if c.pos%m.checkpointEvery == 0 {
+				kept = append(kept, c)
+			}
+		}
+		m.checkpoints = kept
+	}
+}
+
+// rewind returns the model to the latest checkpoint at or before n, or to
+// an empty state if there is none. Store changes still queued from the
+// discarded future are dropped.
+func (m *Model) rewind(n int) {
+	defer m.discardChanges()
+
+	for i := len(m.checkpoints) - 1; i >= 0; i-- {
+		cp := m.checkpoints[i]
+		if cp.pos > n {
+			continue
+		}
+		if err := m.restoreCheckpoint(cp); err != nil {
+			log.Printf("[WARN] replay checkpoint at %d: %v", cp.pos, err)
+			break
+		}
+		return
+	}
+	m.resetState()
+}
+
+// discardChanges drops pending store changes without applying them.
+func (m *Model) discardChanges() {
+	for {
+		select {
+		case _, ok := <-m.changes:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// restoreCheckpoint replaces the store and panels with cp, keeping
+// layout, focus and replay settings.
+func (m *Model) restoreCheckpoint(cp checkpoint) error {
+	if m.store != nil {
+		zr, err := gzip.NewReader(bytes.NewReader(cp.store))
+		if err != nil {
+			return fmt.Errorf("open checkpoint: %w", err)
+		}
+		if err := m.store.Restore(zr); err != nil {
+			return err
+		}
+	}
+	m.arena = cp.arena.Clone()
+	m.timeline = cp.timeline
+	m.graph = cp.graph.Clone()
+	m.inspector = cp.inspector
+	m.footer = cp.footer
+	m.agentEvents = maps.Clone(cp.agentEvents)
+	m.lastTs = cp.lastTs
+	m.inspected = cp.inspected
+	m.applied = cp.pos
+	m.restoreView()
+	return nil
+}
+
+// resetState clears the store and every panel, keeping layout, focus and
+// replay settings.
+func (m *Model) resetState() {
+	if m.store != nil {
+		m.store.Reset()
+	}
+	m.arena = arena.NewModel()
+	m.timeline = timeline.NewModel()
+	m.graph = graph.NewModel()
+	m.inspector = inspector.NewModel()
+	m.footer = footer.NewModel()
+	m.agentEvents = make(map[string]*schema.CanonicalEvent)
+	m.lastTs = time.Time{}
+	m.inspected = ""
+	m.applied = 0
+	m.restoreView()
+}
+
+// restoreView reapplies focus, status and layout to freshly replaced
+// panels.
+func (m *Model) restoreView() {
+	m.arena.SetFocused(m.focused == 0)
+	m.footer.SetStatus(m.baseStatus())
+	if m.width > 0 && m.height > 0 {
+		m.updateLayout()
+	}
+}
+
+// renderLoadReport draws the load report centered over the screen.
+func (m *Model) renderLoadReport() string {
+	report := m.loadReport
+	lines := []string{
+		fmt.Sprintf("Loaded %d events from %d file(s); skipped %d line(s):",
+			report.Events, report.Files, len(report.Skipped)),
+		"",
+	}
+
+	// Keep the box on screen: borders, padding, header and hint take 8 rows
+	limit := m.height - 8
+	if limit < 1 {
+		limit = 1
+	}
+	width := m.width - 8
+	if width < 10 {
+		width = 10
+	}
+	for i, skipped := range report.Skipped {
+		if i == limit-1 && len(report.Skipped) > limit {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(report.Skipped)-i))
+			break
+		}
+		lines = append(lines, truncateStr(skipped.String(), width))
+	}
+	lines = append(lines, "", "Press any key to start playback")
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("#FFA726")).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// updateScrubber shows the replay position in the footer.
+func (m *Model) updateScrubber() {
+	if m.player == nil {
+		return
+	}
+	m.footer.SetReplay(&footer.ReplayState{
+		Position: m.player.Cursor(),
+		Total:    m.player.Total(),
+		Time:     m.player.VirtualTime(),
+		Speed:    m.player.Speed(),
+		Playing:  m.player.IsPlaying(),
+	})
+}
+
+// replayTickCmd schedules the next replay advance.
+func replayTickCmd() tea.Cmd {
+	return tea.Tick(replayTickInterval, func(t time.Time) tea.Msg {
+		return replayTickMsg(t)
+	})
+}
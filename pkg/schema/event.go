@@ -19,6 +19,8 @@ type CanonicalEvent struct {
 	State         AgentState       `json:"state"`
 	Type          EventType        `json:"type"`
 	TaskID        string           `json:"task_id,omitempty"`
+	SpanID        string           `json:"span_id,omitempty"`
+	ParentSpanID  string           `json:"parent_span_id,omitempty"`
 	IntentRef     string           `json:"intent_ref,omitempty"`
 	Payload       json.RawMessage  `json:"payload,omitempty"`
 	Metrics       *EventMetrics    `json:"metrics,omitempty"`